@@ -0,0 +1,104 @@
+package picross
+
+// PicrAmbiguity tells where the solutions of a puzzle differ.
+type PicrAmbiguity struct {
+	// Solutions is the amount of solutions found, and Exhaustive tells whether they are all of them;
+	// otherwise they are a sample bounded by the limit given to Ambiguity.
+	Solutions  uint `json:"solutions"`
+	Exhaustive bool `json:"exhaustive"`
+	// Grid holds the cells forced to the same value by every solution found, Any for the cells that vary.
+	Grid [][]CellState `json:"grid"`
+	// Region numbers, for every cell, the ambiguous region it belongs to, counting from 1; forced cells have 0.
+	// Regions is the amount of regions.
+	Region  [][]uint `json:"region"`
+	Regions uint     `json:"regions"`
+}
+
+// picrAmbiguityLimit is the amount of solutions Ambiguity looks at when no limit is given.
+const picrAmbiguityLimit = 100
+
+// Ambiguity enumerates up to `limit` solutions of a puzzle (zero selects 100) and maps where they differ.
+// Varying cells fall in the same region when some combination of their values never shows among the solutions,
+// so that settling one of them tells something about the other; a classic 2x2 swap makes a region of four cells.
+// Cells of different regions can be settled independently of each other.
+// ErrPicrNoSolution is returned for a puzzle without solutions.
+func (p *PicrPuzzle) Ambiguity(limit int) (*PicrAmbiguity, error) {
+	if limit < 1 {
+		limit = picrAmbiguityLimit
+	}
+	r, err := p.Search(limit + 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Solutions) == 0 {
+		return nil, ErrPicrNoSolution
+	}
+	a := &PicrAmbiguity{Exhaustive: len(r.Solutions) <= limit}
+	sols := r.Solutions
+	if len(sols) > limit {
+		sols = sols[:limit]
+	}
+	a.Solutions = uint(len(sols))
+	a.Grid = picrCopyMap(sols[0])
+	var varying [][2]int
+	for i, row := range a.Grid {
+		for j := range row {
+			for _, s := range sols[1:] {
+				if s[i][j] != row[j] {
+					row[j] = Any
+					varying = append(varying, [2]int{i, j})
+					break
+				}
+			}
+		}
+	}
+	parent := make([]int, len(varying))
+	for k := range parent {
+		parent[k] = k
+	}
+	var find func(k int) int
+	find = func(k int) int {
+		if parent[k] != k {
+			parent[k] = find(parent[k])
+		}
+		return parent[k]
+	}
+	for x := range varying {
+		for y := x + 1; y < len(varying); y++ {
+			if find(x) != find(y) && picrDependent(sols, varying[x], varying[y]) {
+				parent[find(x)] = find(y)
+			}
+		}
+	}
+	a.Region = make([][]uint, len(a.Grid))
+	for i := range a.Region {
+		a.Region[i] = make([]uint, len(a.Grid[i]))
+	}
+	numbers := map[int]uint{}
+	for k, c := range varying {
+		root := find(k)
+		if numbers[root] == 0 {
+			a.Regions += 1
+			numbers[root] = a.Regions
+		}
+		a.Region[c[0]][c[1]] = numbers[root]
+	}
+	return a, nil
+}
+
+// picrDependent tells whether some combination of the values of two cells never shows among solutions
+// where each cell takes both values.
+func picrDependent(sols [][][]CellState, a, b [2]int) bool {
+	var seen [2][2]bool
+	for _, s := range sols {
+		va, vb := 0, 0
+		if s[a[0]][a[1]] == Fill {
+			va = 1
+		}
+		if s[b[0]][b[1]] == Fill {
+			vb = 1
+		}
+		seen[va][vb] = true
+	}
+	return !(seen[0][0] && seen[0][1] && seen[1][0] && seen[1][1])
+}
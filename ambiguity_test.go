@@ -0,0 +1,51 @@
+package picross
+
+import (
+	"testing"
+)
+
+func TestPicrAmbiguity(t *testing.T) {
+	// Three 2x2 swaps, two of them sharing columns.
+	p, _ := NewPicrPuzzleFromGoal(str2Map(`#..#.#..#.
+                                           .#.#..#.#.
+                                           ..........
+                                           #.........
+                                           .#........`))
+	a, err := p.Ambiguity(0)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if a.Solutions != 8 || !a.Exhaustive || a.Regions != 3 {
+		t.Errorf(`unexpected ambiguity: %+v`, a)
+	}
+	expected := [][]uint{
+		{1, 1, 0, 0, 0, 2, 2, 0, 0, 0},
+		{1, 1, 0, 0, 0, 2, 2, 0, 0, 0},
+		{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{3, 3, 0, 0, 0, 0, 0, 0, 0, 0},
+		{3, 3, 0, 0, 0, 0, 0, 0, 0, 0},
+	}
+	if !areSlices2Equal(a.Region, expected) {
+		t.Errorf(`unexpected regions: %v`, a.Region)
+	}
+	if a.Grid[0][0] != Any || a.Grid[0][3] != Fill || a.Grid[2][0] != Gap {
+		t.Errorf(`unexpected grid: %v`, a.Grid)
+	}
+	if a, err = p.Ambiguity(3); err != nil || a.Solutions != 3 || a.Exhaustive {
+		t.Errorf(`unexpected bounded ambiguity: %+v %v`, a, err)
+	}
+}
+
+func TestPicrAmbiguityUnique(t *testing.T) {
+	a, err := horsePuzzle().Ambiguity(0)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if a.Solutions != 1 || !a.Exhaustive || a.Regions != 0 || !areSlices2Equal(a.Grid, horseGoal()) {
+		t.Errorf(`unexpected ambiguity: %+v`, a)
+	}
+	bad := &PicrPuzzle{RowClues: [][]uint{{1}}, ColClues: [][]uint{{0}}}
+	if _, err := bad.Ambiguity(0); err != ErrPicrNoSolution {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+}
@@ -0,0 +1,181 @@
+package picross
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// PicrSymmetry is one of the eight symmetries of a rectangular grid.
+// It is applied as a transposition, if any, followed by the mirrorings, if any.
+type PicrSymmetry uint8
+
+const (
+	PicrIdentity PicrSymmetry = 0
+	// PicrMirrorH mirrors left to right.
+	PicrMirrorH PicrSymmetry = 1
+	// PicrMirrorV mirrors top to bottom.
+	PicrMirrorV   PicrSymmetry = 2
+	PicrRotate180 PicrSymmetry = PicrMirrorH | PicrMirrorV
+	// PicrTranspose swaps rows and columns.
+	PicrTranspose PicrSymmetry = 4
+	// PicrRotate90 rotates clockwise.
+	PicrRotate90      PicrSymmetry = PicrTranspose | PicrMirrorH
+	PicrRotate270     PicrSymmetry = PicrTranspose | PicrMirrorV
+	PicrAntiTranspose PicrSymmetry = PicrTranspose | PicrMirrorH | PicrMirrorV
+)
+
+// PicrSymmetries lists the eight symmetries of a grid.
+var PicrSymmetries = []PicrSymmetry{
+	PicrIdentity, PicrMirrorH, PicrMirrorV, PicrRotate180,
+	PicrTranspose, PicrRotate90, PicrRotate270, PicrAntiTranspose,
+}
+
+func (s PicrSymmetry) String() string {
+	switch s {
+	case PicrIdentity:
+		return "identity"
+	case PicrMirrorH:
+		return "mirror-h"
+	case PicrMirrorV:
+		return "mirror-v"
+	case PicrRotate180:
+		return "rotate-180"
+	case PicrTranspose:
+		return "transpose"
+	case PicrRotate90:
+		return "rotate-90"
+	case PicrRotate270:
+		return "rotate-270"
+	case PicrAntiTranspose:
+		return "anti-transpose"
+	}
+	return fmt.Sprintf("PicrSymmetry(%d)", uint8(s))
+}
+
+// ParsePicrSymmetry returns the symmetry with a name, as given by PicrSymmetry.String.
+func ParsePicrSymmetry(name string) (PicrSymmetry, error) {
+	for _, s := range PicrSymmetries {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return PicrIdentity, fmt.Errorf("PicrSymmetry: unknown symmetry %q", name)
+}
+
+// picrReverseClue returns a clue read from the other end of its line.
+func picrReverseClue(clue []uint) []uint {
+	ans := make([]uint, len(clue))
+	for i, v := range clue {
+		ans[len(clue)-1-i] = v
+	}
+	return ans
+}
+
+// picrSymmetryClues returns the row and column clues of a puzzle transformed by a symmetry.
+func picrSymmetryClues(rows, cols [][]uint, s PicrSymmetry) ([][]uint, [][]uint) {
+	if s&PicrTranspose != 0 {
+		rows, cols = cols, rows
+	}
+	newRows := make([][]uint, len(rows))
+	for i, clue := range rows {
+		if s&PicrMirrorV != 0 {
+			i = len(rows) - 1 - i
+		}
+		if s&PicrMirrorH != 0 {
+			clue = picrReverseClue(clue)
+		} else {
+			clue = append([]uint{}, clue...)
+		}
+		newRows[i] = clue
+	}
+	newCols := make([][]uint, len(cols))
+	for j, clue := range cols {
+		if s&PicrMirrorH != 0 {
+			j = len(cols) - 1 - j
+		}
+		if s&PicrMirrorV != 0 {
+			clue = picrReverseClue(clue)
+		} else {
+			clue = append([]uint{}, clue...)
+		}
+		newCols[j] = clue
+	}
+	return newRows, newCols
+}
+
+// picrSymmetryGrid returns a grid transformed by a symmetry.
+func picrSymmetryGrid(mat [][]CellState, s PicrSymmetry) [][]CellState {
+	if s&PicrTranspose != 0 {
+		mat = picrTranspose(mat)
+	} else {
+		mat = picrCopyMap(mat)
+	}
+	if s&PicrMirrorV != 0 {
+		for i, j := 0, len(mat)-1; i < j; i, j = i+1, j-1 {
+			mat[i], mat[j] = mat[j], mat[i]
+		}
+	}
+	if s&PicrMirrorH != 0 {
+		for _, row := range mat {
+			for i, j := 0, len(row)-1; i < j; i, j = i+1, j-1 {
+				row[i], row[j] = row[j], row[i]
+			}
+		}
+	}
+	return mat
+}
+
+// picrClueKey encodes the clues of a puzzle as text: the size, then the row clues, then the column clues,
+// as in "2x3/1,1 1,0/2,1,1", with runs separated by spaces and empty lines as '0'.
+func picrClueKey(rows, cols [][]uint) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%dx%d", len(cols), len(rows))
+	for _, clues := range [][][]uint{rows, cols} {
+		for i, clue := range clues {
+			if i == 0 {
+				b.WriteByte('/')
+			} else {
+				b.WriteByte(',')
+			}
+			b.WriteString(picrFormatClue(clue))
+		}
+	}
+	return b.String()
+}
+
+// canonicalSymmetry returns the symmetry that takes the puzzle to its canonical form,
+// together with the clue key of that form.
+func (p *PicrPuzzle) canonicalSymmetry() (PicrSymmetry, string) {
+	best, bestKey := PicrIdentity, ""
+	for _, s := range PicrSymmetries {
+		key := picrClueKey(picrSymmetryClues(p.RowClues, p.ColClues, s))
+		if s == PicrIdentity || key < bestKey {
+			best, bestKey = s, key
+		}
+	}
+	return best, bestKey
+}
+
+// Canonical returns the canonical form of a puzzle, which is the same for every mirroring, rotation
+// or transposition of it, together with the symmetry that takes the puzzle to that form.
+// The canonical form is the transformed puzzle whose clue key sorts first; its goal, if any, is transformed alike.
+func (p *PicrPuzzle) Canonical() (*PicrPuzzle, PicrSymmetry) {
+	s, _ := p.canonicalSymmetry()
+	q := &PicrPuzzle{Title: p.Title}
+	q.RowClues, q.ColClues = picrSymmetryClues(p.RowClues, p.ColClues, s)
+	if p.Goal != nil {
+		q.Goal = picrSymmetryGrid(p.Goal, s)
+	}
+	return q, s
+}
+
+// Hash returns a stable content hash of a puzzle: the hex encoded SHA-256 of the clues of its canonical form.
+// Puzzles that differ only by mirroring, rotation or transposition share the hash;
+// titles and goals are not taken into account.
+func (p *PicrPuzzle) Hash() string {
+	_, key := p.canonicalSymmetry()
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
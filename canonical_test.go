@@ -0,0 +1,90 @@
+package picross
+
+import (
+	"testing"
+)
+
+func horseGoal() [][]CellState {
+	return str2Map(`###..
+                    .#..#
+                    .####
+                    .###.
+                    .#.#.`)
+}
+
+func TestPicrSymmetryGrid(t *testing.T) {
+	goal := str2Map(`##.
+                     ...`)
+	expected := map[PicrSymmetry]string{
+		PicrIdentity:      `##. ...`,
+		PicrMirrorH:       `.## ...`,
+		PicrMirrorV:       `... ##.`,
+		PicrRotate180:     `... .##`,
+		PicrTranspose:     `#. #. ..`,
+		PicrRotate90:      `.# .# ..`,
+		PicrRotate270:     `.. #. #.`,
+		PicrAntiTranspose: `.. .# .#`,
+	}
+	for _, s := range PicrSymmetries {
+		got := picrSymmetryGrid(goal, s)
+		text := ``
+		for i, row := range got {
+			if i > 0 {
+				text += ` `
+			}
+			text += picrFormatRow(row)
+		}
+		if text != expected[s] {
+			t.Errorf(`%v: expected %q, got %q`, s, expected[s], text)
+		}
+	}
+	if picrFormatRow(goal[0]) != `##.` {
+		t.Errorf(`grid modified: %v`, goal)
+	}
+}
+
+func TestPicrSymmetryClues(t *testing.T) {
+	p, _ := NewPicrPuzzleFromGoal(horseGoal())
+	for _, s := range PicrSymmetries {
+		q, _ := NewPicrPuzzleFromGoal(picrSymmetryGrid(p.Goal, s))
+		rows, cols := picrSymmetryClues(p.RowClues, p.ColClues, s)
+		if picrClueKey(rows, cols) != picrClueKey(q.RowClues, q.ColClues) {
+			t.Errorf(`%v: clues mismatch: expected %v %v, got %v %v`, s, q.RowClues, q.ColClues, rows, cols)
+		}
+	}
+}
+
+func TestPicrCanonical(t *testing.T) {
+	p, _ := NewPicrPuzzleFromGoal(horseGoal())
+	c, _ := p.Canonical()
+	key := picrClueKey(c.RowClues, c.ColClues)
+	for _, s := range PicrSymmetries {
+		q, _ := NewPicrPuzzleFromGoal(picrSymmetryGrid(p.Goal, s))
+		qc, qs := q.Canonical()
+		if picrClueKey(qc.RowClues, qc.ColClues) != key || q.Hash() != p.Hash() {
+			t.Errorf(`%v: canonical form mismatch: %v`, s, picrClueKey(qc.RowClues, qc.ColClues))
+		}
+		if !areSlices2Equal(picrSymmetryGrid(q.Goal, qs), qc.Goal) || !picrGoalMatches(qc) {
+			t.Errorf(`%v: canonical goal mismatch: %v`, s, qc.Goal)
+		}
+	}
+	if h := p.Hash(); len(h) != 64 || h != horsePuzzle().Hash() {
+		t.Errorf(`unexpected hash: %q`, h)
+	}
+	goal := horseGoal()
+	goal[0][0] = Gap
+	q, _ := NewPicrPuzzleFromGoal(goal)
+	if q.Hash() == p.Hash() {
+		t.Errorf(`hash collision`)
+	}
+}
+
+func TestPicrClueKey(t *testing.T) {
+	key := picrClueKey([][]uint{{1}, {1, 1}, {}}, [][]uint{{2}, {1}, {0}})
+	if key != `3x3/1,1 1,0/2,1,0` {
+		t.Errorf(`unexpected key: %q`, key)
+	}
+	if s := PicrRotate90.String(); s != `rotate-90` {
+		t.Errorf(`unexpected name: %q`, s)
+	}
+}
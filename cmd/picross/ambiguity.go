@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["ambiguity"] = command{summary: "map where the solutions of a puzzle differ", run: runAmbiguity}
+}
+
+func runAmbiguity(args []string, env *environ) int {
+	fs := flag.NewFlagSet("ambiguity", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross ambiguity [flags] [file]\n\n"+
+			"Enumerates the solutions of a puzzle read from file (or stdin) and draws the cells they agree on,\n"+
+			"'#' filled and '.' gaps, marking the cells that vary by a letter per independent ambiguous region.\n\n")
+		fs.PrintDefaults()
+	}
+	jsonOut := fs.Bool("json", false, "print a JSON report")
+	limit := fs.Int("limit", 0, "largest amount of solutions looked at (0 selects the default)")
+	out := fs.String("o", "", "also draw the map into this .svg or .png file")
+	format := fs.String("format", "", "input format, detected from the content when empty")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return exitInvalid
+	}
+	p, err := loadPuzzle(fs.Arg(0), *format, env)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	a, err := p.Ambiguity(*limit)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitCode(err)
+	}
+	if *out != "" {
+		if err := renderAmbiguity(*out, p, a); err != nil {
+			fmt.Fprintf(env.stderr, "picross: %v\n", err)
+			return exitInvalid
+		}
+	}
+	code := exitSolved
+	if a.Solutions > 1 || !a.Exhaustive {
+		code = exitStalled
+	}
+	if *jsonOut {
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(a)
+		return code
+	}
+	regions := fmt.Sprintf("%d ambiguous regions", a.Regions)
+	if a.Regions == 1 {
+		regions = "1 ambiguous region"
+	}
+	switch {
+	case a.Solutions == 1 && a.Exhaustive:
+		fmt.Fprintf(env.stdout, "unique solution\n")
+	case a.Exhaustive:
+		fmt.Fprintf(env.stdout, "%d solutions, %s\n", a.Solutions, regions)
+	default:
+		fmt.Fprintf(env.stdout, "more than %d solutions, %s among the first ones\n", a.Solutions, regions)
+	}
+	for _, line := range ambiguityLines(a) {
+		fmt.Fprintf(env.stdout, "  %s\n", line)
+	}
+	return code
+}
+
+// ambiguityLines draws an ambiguity map, with letters for the regions, '?' past the alphabet.
+func ambiguityLines(a *picross.PicrAmbiguity) []string {
+	ans := gridLines(a.Grid)
+	for i, row := range a.Region {
+		line := []byte(ans[i])
+		for j, v := range row {
+			switch {
+			case v == 0:
+			case v <= 26:
+				line[j] = byte('a' + v - 1)
+			default:
+				line[j] = '?'
+			}
+		}
+		ans[i] = string(line)
+	}
+	return ans
+}
+
+// renderAmbiguity draws the forced cells of an ambiguity map, with the regions highlighted, into a picture file.
+func renderAmbiguity(path string, p *picross.PicrPuzzle, a *picross.PicrAmbiguity) error {
+	var render func(io.Writer, *picross.PicrPuzzle, picross.PicrRenderOptions) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".svg":
+		render = picross.RenderPicrSVG
+	case ".png":
+		render = picross.RenderPicrPNG
+	default:
+		return fmt.Errorf("cannot draw into %q: use a .svg or .png file", path)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = render(f, p, picross.PicrRenderOptions{State: a.Grid, Highlight: a.Region})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func TestAmbiguity(t *testing.T) {
+	code, stdout, stderr := runCmd(ambiguousText, `ambiguity`)
+	if code != exitStalled {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	if stdout != "2 solutions, 1 ambiguous region\n  a#a.\n  #..#\n  a#a.\n  ....\n" {
+		t.Errorf(`unexpected output: %q`, stdout)
+	}
+	path := filepath.Join(t.TempDir(), `map.svg`)
+	code, stdout, _ = runCmd(ambiguousText, `ambiguity`, `-json`, `-limit`, `1`, `-o`, path)
+	var a picross.PicrAmbiguity
+	if err := json.Unmarshal([]byte(stdout), &a); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if code != exitStalled || a.Solutions != 1 || a.Exhaustive {
+		t.Errorf(`unexpected report: %v %+v`, code, a)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Errorf(`picture not written: %v`, err)
+	}
+	code, stdout, _ = runCmd(horseTatham, `ambiguity`)
+	if code != exitSolved || stdout != "unique solution\n  ###..\n  .#..#\n  .####\n  .###.\n  .#.#.\n" {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+}
+
+func TestAmbiguityFail(t *testing.T) {
+	checks := []struct {
+		stdin string
+		args  []string
+		code  int
+	}{
+		{"2x2:2/0/2/0\n", []string{`ambiguity`}, exitContradiction},
+		{ambiguousText, []string{`ambiguity`, `-o`, `map.gif`}, exitInvalid},
+		{ambiguousText, []string{`ambiguity`, `a`, `b`}, exitInvalid},
+		{"garbage\n", []string{`ambiguity`}, exitInvalid},
+	}
+	for _, c := range checks {
+		if code, _, stderr := runCmd(c.stdin, c.args...); code != c.code {
+			t.Errorf(`%q %v: unexpected exit code %v: %q`, c.stdin, c.args, code, stderr)
+		}
+	}
+}
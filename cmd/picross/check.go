@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["check"] = command{summary: "report whether a puzzle is well-formed, unique and solvable", run: runCheck}
+}
+
+// Outcomes of the check command, by exit code.
+var checkStatusNames = map[int]string{
+	exitSolved:        "unique",
+	exitContradiction: "none",
+	exitStalled:       "ambiguous",
+	exitInvalid:       "invalid",
+	exitTimeout:       "timeout",
+}
+
+// checkReport is the JSON output of the check command.
+type checkReport struct {
+	Status     string   `json:"status"`
+	Title      string   `json:"title,omitempty"`
+	Width      uint     `json:"width,omitempty"`
+	Height     uint     `json:"height,omitempty"`
+	WellFormed bool     `json:"well_formed"`
+	Problems   []string `json:"problems,omitempty"`
+	Solutions  int      `json:"solutions"`
+	// SolvedBy is the weakest technique that solves the puzzle: "line", "probing" or "search".
+	SolvedBy string `json:"solved_by,omitempty"`
+	// Unknown is the amount of cells left unknown by line logic alone.
+	Unknown  int      `json:"unknown"`
+	Guesses  uint     `json:"guesses"`
+	Solution []string `json:"solution,omitempty"`
+	// Diff pictures two solutions of an ambiguous puzzle, with '!' marking the cells where they differ.
+	Diff  []string `json:"diff,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+func runCheck(args []string, env *environ) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross check [flags] [file]\n\nReads a puzzle from file (or stdin) and reports whether it is well-formed,\nhow many solutions it has and which technique is needed to solve it.\n\n")
+		fs.PrintDefaults()
+	}
+	jsonOut := fs.Bool("json", false, "print a JSON report")
+	timeout := fs.Duration("timeout", 0, "give up after this long (0 waits forever)")
+	format := fs.String("format", "", "input format, detected from the content when empty")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return exitInvalid
+	}
+	p, err := loadPuzzle(fs.Arg(0), *format, env)
+	if err != nil {
+		report := checkReport{Status: checkStatusNames[exitInvalid], Error: err.Error()}
+		return printCheckReport(env, report, *jsonOut, exitInvalid)
+	}
+	type outcome struct {
+		report checkReport
+		code   int
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		report, code := checkPuzzle(p)
+		done <- outcome{report, code}
+	}()
+	var expired <-chan time.Time
+	if *timeout > 0 {
+		expired = time.After(*timeout)
+	}
+	select {
+	case o := <-done:
+		return printCheckReport(env, o.report, *jsonOut, o.code)
+	case <-expired:
+		report := checkReport{Status: checkStatusNames[exitTimeout], Title: p.Title, Width: p.Width(), Height: p.Height(),
+			Error: fmt.Errorf("%w after %v", errTimeout, *timeout).Error()}
+		return printCheckReport(env, report, *jsonOut, exitTimeout)
+	}
+}
+
+// checkPuzzle examines a puzzle with increasingly stronger techniques, returning the report and its exit code.
+func checkPuzzle(p *picross.PicrPuzzle) (checkReport, int) {
+	report := checkReport{Title: p.Title, Width: p.Width(), Height: p.Height()}
+	report.Problems = checkProblems(p)
+	report.WellFormed = len(report.Problems) == 0
+	r, err := p.Search(2)
+	if err != nil {
+		report.Status, report.Error = checkStatusNames[exitInvalid], err.Error()
+		report.WellFormed = false
+		return report, exitInvalid
+	}
+	report.Solutions, report.Guesses = len(r.Solutions), r.Guesses
+	switch len(r.Solutions) {
+	case 0:
+		report.Status = checkStatusNames[exitContradiction]
+		return report, exitContradiction
+	case 1:
+		report.Status = checkStatusNames[exitSolved]
+		report.Solution = gridLines(r.Solutions[0])
+	default:
+		report.Status = checkStatusNames[exitStalled]
+		report.Diff = diffLines(r.Solutions[0], r.Solutions[1])
+	}
+	s, err := p.NewSolver(nil)
+	if err != nil {
+		report.Status, report.Error = checkStatusNames[exitInvalid], err.Error()
+		return report, exitInvalid
+	}
+	err = s.Solve()
+	report.Unknown = countUnknown(s.State())
+	if report.Solutions > 1 {
+		return report, exitStalled
+	}
+	report.SolvedBy = "line"
+	if err == nil {
+		return report, exitSolved
+	}
+	report.SolvedBy = "search"
+	if pr, err := p.Probe(); err == nil && countUnknown(pr.Grid) == 0 {
+		report.SolvedBy = "probing"
+	}
+	return report, exitSolved
+}
+
+// checkProblems lists what is wrong with the clues of a puzzle, and with its goal when it has one.
+func checkProblems(p *picross.PicrPuzzle) []string {
+	var ans []string
+	rowTotal, colTotal := clueTotal(p.RowClues), clueTotal(p.ColClues)
+	if rowTotal != colTotal {
+		ans = append(ans, fmt.Sprintf("row clues fill %d cells but column clues fill %d", rowTotal, colTotal))
+	}
+	if p.Goal != nil {
+		if _, err := picross.NewPicrPuzzleFromGoal(p.Goal); err != nil {
+			ans = append(ans, err.Error())
+		} else if !p.GoalMatches() {
+			ans = append(ans, "goal picture does not match the clues")
+		}
+	}
+	return ans
+}
+
+// clueTotal returns the amount of filled cells requested by a set of clues.
+func clueTotal(clues [][]uint) uint {
+	var ans uint
+	for _, clue := range clues {
+		for _, n := range clue {
+			ans += n
+		}
+	}
+	return ans
+}
+
+// diffLines pictures two grids of the same size, with '!' where they differ.
+func diffLines(a, b [][]picross.CellState) []string {
+	ans := gridLines(a)
+	for i, row := range a {
+		line := []byte(ans[i])
+		for j, v := range row {
+			if v != b[i][j] {
+				line[j] = '!'
+			}
+		}
+		ans[i] = string(line)
+	}
+	return ans
+}
+
+// printCheckReport writes the result of the check command, returning its exit code.
+func printCheckReport(env *environ, report checkReport, jsonOut bool, code int) int {
+	if jsonOut {
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		return code
+	}
+	if report.Error != "" {
+		fmt.Fprintf(env.stderr, "picross: %s: %s\n", report.Status, report.Error)
+		return code
+	}
+	if report.Title != "" {
+		fmt.Fprintf(env.stdout, "title:       %s\n", report.Title)
+	}
+	fmt.Fprintf(env.stdout, "size:        %dx%d\n", report.Width, report.Height)
+	if report.WellFormed {
+		fmt.Fprintf(env.stdout, "well-formed: yes\n")
+	} else {
+		fmt.Fprintf(env.stdout, "well-formed: no\n")
+	}
+	for _, problem := range report.Problems {
+		fmt.Fprintf(env.stdout, "  - %s\n", problem)
+	}
+	switch report.Status {
+	case checkStatusNames[exitStalled]:
+		fmt.Fprintf(env.stdout, "solutions:   more than one\n")
+	default:
+		fmt.Fprintf(env.stdout, "solutions:   %s\n", report.Status)
+	}
+	if report.SolvedBy != "" {
+		fmt.Fprintf(env.stdout, "solved by:   %s\n", map[string]string{
+			"line": "line logic", "probing": "line logic and probing", "search": "search",
+		}[report.SolvedBy])
+	}
+	if report.Solutions > 0 {
+		fmt.Fprintf(env.stdout, "unknown after line logic: %d of %d cells\n", report.Unknown, report.Width*report.Height)
+	}
+	if report.Diff != nil {
+		fmt.Fprintf(env.stdout, "\ntwo solutions, differing at '!':\n")
+		for _, line := range report.Diff {
+			fmt.Fprintln(env.stdout, line)
+		}
+	}
+	return code
+}
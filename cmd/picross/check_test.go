@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func TestCheck(t *testing.T) {
+	code, stdout, _ := runCmd(horseTatham, `check`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v`, code)
+	}
+	for _, line := range []string{"well-formed: yes\n", "solutions:   unique\n", "solved by:   line logic\n", "unknown after line logic: 0 of 25 cells\n"} {
+		if !strings.Contains(stdout, line) {
+			t.Errorf(`%q not found in output: %q`, line, stdout)
+		}
+	}
+	code, stdout, _ = runCmd("5x7:1.2/1.1.2/2/1.1.1/2/4/1/1/1.1/1/2.1/2\n", `check`, `--json`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v`, code)
+	}
+	var report checkReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if report.Status != `unique` || report.SolvedBy != `probing` || report.Unknown == 0 || report.Solutions != 1 || len(report.Solution) != 7 {
+		t.Errorf(`unexpected report: %+v`, report)
+	}
+	// Empty lines clued as {0} still match an empty goal line.
+	pack := `{"format": "picross-pack", "puzzles": [{"id": "a", "width": 2, "height": 2, "rows": [[1], [0]], "cols": [[1], [0]], "solution": ["#.", ".."]}]}`
+	code, stdout, _ = runCmd(pack, `check`)
+	if code != exitSolved || !strings.Contains(stdout, "well-formed: yes\n") {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+}
+
+func TestCheckAmbiguous(t *testing.T) {
+	code, stdout, _ := runCmd("3x2:1/1/0/1/1\n", `check`)
+	if code != exitStalled {
+		t.Fatalf(`unexpected exit code: %v`, code)
+	}
+	if !strings.Contains(stdout, "solutions:   more than one\n") || !strings.HasSuffix(stdout, "!!.\n!!.\n") {
+		t.Errorf(`unexpected output: %q`, stdout)
+	}
+	code, stdout, _ = runCmd("3x2:1/1/0/1/1\n", `check`, `--json`)
+	var report checkReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if code != exitStalled || report.Status != `ambiguous` || report.SolvedBy != `` || report.Unknown != 4 || len(report.Diff) != 2 {
+		t.Errorf(`unexpected report: %v %+v`, code, report)
+	}
+}
+
+func TestCheckFail(t *testing.T) {
+	code, stdout, _ := runCmd("2x2:1/2/2/2\n", `check`)
+	if code != exitContradiction {
+		t.Fatalf(`unexpected exit code: %v`, code)
+	}
+	if !strings.Contains(stdout, "well-formed: no\n  - row clues fill 4 cells but column clues fill 3\n") || !strings.Contains(stdout, "solutions:   none\n") {
+		t.Errorf(`unexpected output: %q`, stdout)
+	}
+	p, _ := picross.NewPicrPuzzle([][]uint{{1}}, [][]uint{{1}, {0}})
+	p.Goal = [][]picross.CellState{{picross.Gap, picross.Fill}}
+	if problems := checkProblems(p); len(problems) != 1 {
+		t.Errorf(`unexpected problems: %q`, problems)
+	}
+	if code, _, stderr := runCmd("garbage\n", `check`); code != exitInvalid || stderr == `` {
+		t.Errorf(`unexpected result: %v %q`, code, stderr)
+	}
+	if code, _, _ := runCmd(horseTatham, `check`, `a`, `b`); code != exitInvalid {
+		t.Errorf(`unexpected exit code: %v`, code)
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["convert"] = command{summary: "convert puzzles between formats", run: runConvert}
+}
+
+// namedPuzzle is a puzzle read by the convert command, with the name its output takes.
+type namedPuzzle struct {
+	name   string
+	puzzle *picross.PicrPuzzle
+}
+
+func runConvert(args []string, env *environ) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross convert [flags] [input...]\n\n"+
+			"Reads puzzles from files, directories and packs (or stdin) and writes them in another format.\n"+
+			"A single puzzle goes to stdout or to the -o file; many puzzles go to the -o directory,\n"+
+			"or into a single pack when converting to the pack format.\n\nformats:\n")
+		for _, f := range picross.PicrFormats() {
+			var modes []string
+			if f.Decode != nil {
+				modes = append(modes, "read")
+			}
+			if f.Encode != nil {
+				modes = append(modes, "write")
+			}
+			fmt.Fprintf(env.stderr, "  %-14s %-11s %s\n", f.Name, strings.Join(modes, ","), strings.Join(f.Extensions, " "))
+		}
+		fmt.Fprintf(env.stderr, "\n")
+		flags.PrintDefaults()
+	}
+	from := flags.String("from", "", "input format, detected from the content when empty")
+	to := flags.String("to", "", "output format, chosen from the -o file name when empty")
+	out := flags.String("o", "", "output file or directory (default stdout)")
+	solve := flags.Bool("solve", false, "solve puzzles lacking a goal grid and embed it")
+	if err := flags.Parse(args); err != nil {
+		return exitInvalid
+	}
+	inputs := flags.Args()
+	if len(inputs) < 1 {
+		inputs = []string{"-"}
+	}
+	failed := false
+	dirWanted := false
+	var puzzles []namedPuzzle
+	for _, input := range inputs {
+		if info, err := os.Stat(input); err == nil && info.IsDir() {
+			dirWanted = true
+		}
+		found, err := readPuzzles(input, *from, env)
+		if err != nil {
+			fmt.Fprintf(env.stderr, "picross: %v\n", err)
+			failed = true
+		}
+		puzzles = append(puzzles, found...)
+	}
+	if *solve {
+		for _, np := range puzzles {
+			if err := embedGoal(np.puzzle); err != nil {
+				fmt.Fprintf(env.stderr, "picross: %s: %v\n", np.name, err)
+				failed = true
+			}
+		}
+	}
+	if err := writePuzzles(puzzles, *to, *out, dirWanted, env); err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	if failed {
+		return exitInvalid
+	}
+	return exitSolved
+}
+
+// readPuzzles reads the puzzles of an input: stdin ("-"), a directory, a pack or a single puzzle file.
+func readPuzzles(input string, format string, env *environ) ([]namedPuzzle, error) {
+	if input == "-" {
+		p, err := loadPuzzle(input, format, env)
+		if err != nil {
+			return nil, err
+		}
+		return []namedPuzzle{{name: "stdin", puzzle: p}}, nil
+	}
+	info, err := os.Stat(input)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return readPuzzleFile(input, format, env)
+	}
+	var ans []namedPuzzle
+	var errs []string
+	err = filepath.WalkDir(input, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if f, ok := picross.PicrFormatByPath(path); !ok || f.Decode == nil {
+			return nil
+		}
+		found, err := readPuzzleFile(path, format, env)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		ans = append(ans, found...)
+		return nil
+	})
+	if err != nil {
+		return ans, err
+	}
+	if len(errs) > 0 {
+		return ans, errors.New(strings.Join(errs, "\npicross: "))
+	}
+	return ans, nil
+}
+
+// readPuzzleFile reads a file holding a single puzzle, or every puzzle of a pack.
+func readPuzzleFile(path string, format string, env *environ) ([]namedPuzzle, error) {
+	name := trimFormatExt(filepath.Base(path))
+	isPack := format == "pack"
+	if format == "" {
+		var err error
+		if isPack, err = sniffPack(path); err != nil {
+			return nil, err
+		}
+	}
+	if !isPack {
+		p, err := loadPuzzle(path, format, env)
+		if err != nil {
+			return nil, err
+		}
+		return []namedPuzzle{{name: name, puzzle: p}}, nil
+	}
+	pk, err := picross.OpenPicrPack(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	ans := make([]namedPuzzle, 0, len(pk.Entries))
+	for _, e := range pk.Entries {
+		p, err := e.Puzzle()
+		if err != nil {
+			return ans, fmt.Errorf("%s: %v", path, err)
+		}
+		ans = append(ans, namedPuzzle{name: name + "-" + e.ID, puzzle: p})
+	}
+	return ans, nil
+}
+
+// sniffPack tells whether a file holds a pack, from its content rather than its name,
+// as Load would detect it.
+func sniffPack(path string) (bool, error) {
+	f, ok := picross.PicrFormatByName("pack")
+	if !ok || f.Sniff == nil {
+		return false, nil
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+	head := make([]byte, 1<<16)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	return f.Sniff(head[:n]), nil
+}
+
+// trimFormatExt removes from a file name the extension of its format, if any.
+func trimFormatExt(base string) string {
+	f, ok := picross.PicrFormatByPath(base)
+	if !ok {
+		return strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	lower := strings.ToLower(base)
+	for _, ext := range f.Extensions {
+		if strings.HasSuffix(lower, ext) {
+			return base[:len(base)-len(ext)]
+		}
+	}
+	return base
+}
+
+// embedGoal solves a puzzle that lacks a goal grid, which it then gets.
+func embedGoal(p *picross.PicrPuzzle) error {
+	if p.Goal != nil {
+		return nil
+	}
+	r, err := p.Search(2)
+	if err != nil {
+		return err
+	}
+	switch len(r.Solutions) {
+	case 0:
+		return picross.ErrPicrNoSolution
+	case 1:
+		p.Goal = r.Solutions[0]
+		return nil
+	}
+	return errors.New("puzzle has more than one solution")
+}
+
+// writePuzzles writes the converted puzzles to stdout, a file, a pack or a directory.
+// A directory is written, and created if needed, when `out` is one or ends with a path separator,
+// when more than one puzzle is written, or when `dirWanted` tells so, as for puzzles read from a directory.
+func writePuzzles(puzzles []namedPuzzle, format string, out string, dirWanted bool, env *environ) error {
+	info, err := os.Stat(out)
+	outIsDir := out != "" && (err == nil && info.IsDir() || strings.HasSuffix(out, "/") || strings.HasSuffix(out, string(filepath.Separator)))
+	if format == "" {
+		if out == "" || outIsDir {
+			return errors.New("missing output format (-to)")
+		}
+		f, ok := picross.PicrFormatByPath(out)
+		if !ok {
+			return fmt.Errorf("no format for file name %q", out)
+		}
+		format = f.Name
+	}
+	f, ok := picross.PicrFormatByName(format)
+	if !ok || f.Encode == nil {
+		return fmt.Errorf("cannot write format %q", format)
+	}
+	if format == "pack" {
+		return writePack(puzzles, out, env)
+	}
+	toDir := outIsDir || out != "" && (dirWanted || len(puzzles) > 1)
+	if len(puzzles) == 1 && !toDir {
+		if out == "" {
+			return picross.Save(env.stdout, puzzles[0].puzzle, format)
+		}
+		return picross.SaveFile(out, puzzles[0].puzzle, format)
+	}
+	if len(puzzles) == 0 {
+		return nil
+	}
+	if out == "" {
+		return fmt.Errorf("%d puzzles need an output directory (-o)", len(puzzles))
+	}
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return err
+	}
+	ext := ""
+	if len(f.Extensions) > 0 {
+		ext = f.Extensions[0]
+	}
+	used := map[string]bool{}
+	for _, np := range puzzles {
+		name := uniqueName(np.name, used)
+		if err := picross.SaveFile(filepath.Join(out, name+ext), np.puzzle, format); err != nil {
+			return fmt.Errorf("%s: %v", np.name, err)
+		}
+	}
+	return nil
+}
+
+// writePack gathers the converted puzzles into a single pack.
+func writePack(puzzles []namedPuzzle, out string, env *environ) error {
+	pk := picross.NewPicrPack(out, "")
+	if len(puzzles) == 1 {
+		pk.Title = puzzles[0].puzzle.Title
+	}
+	used := map[string]bool{}
+	for _, np := range puzzles {
+		e, err := picross.NewPicrPackEntry(uniqueName(np.name, used), np.puzzle)
+		if err != nil {
+			return fmt.Errorf("%s: %v", np.name, err)
+		}
+		if err := pk.Append(e); err != nil {
+			return err
+		}
+	}
+	if out == "" {
+		return pk.Write(env.stdout)
+	}
+	return pk.Save()
+}
+
+// uniqueName returns `name`, suffixed by a number if it was already used.
+func uniqueName(name string, used map[string]bool) string {
+	ans := name
+	for n := 2; used[ans]; n++ {
+		ans = fmt.Sprintf("%s-%d", name, n)
+	}
+	used[ans] = true
+	return ans
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func TestConvert(t *testing.T) {
+	code, stdout, stderr := runCmd(horseTatham, `convert`, `-to`, `text`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	if stdout != "rows: 3 | 1 1 | 4 | 3 | 1 1\ncols: 1 | 5 | 1 2 | 3 | 2\n" {
+		t.Errorf(`unexpected output: %q`, stdout)
+	}
+	code, stdout, _ = runCmd(horseTatham, `convert`, `-to`, `text`, `-solve`)
+	if code != exitSolved || !strings.HasSuffix(stdout, "\n###..\n.#..#\n.####\n.###.\n.#.#.\n") {
+		t.Errorf(`unexpected output: %v %q`, code, stdout)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, `horse.non`)
+	if code, _, stderr = runCmd(horseTatham, `convert`, `-o`, path); code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	p, err := picross.LoadFile(path)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if p.Width() != 5 || p.Height() != 5 || len(p.RowClues[1]) != 2 {
+		t.Errorf(`unexpected puzzle: %+v`, p)
+	}
+}
+
+func TestConvertMany(t *testing.T) {
+	in, out := t.TempDir(), filepath.Join(t.TempDir(), `out`)
+	os.WriteFile(filepath.Join(in, `horse.tatham`), []byte(horseTatham), 0644)
+	os.MkdirAll(filepath.Join(in, `sub`), 0755)
+	os.WriteFile(filepath.Join(in, `sub`, `dot.txt`), []byte("rows: 1\ncols: 1\n"), 0644)
+	os.WriteFile(filepath.Join(in, `notes.md`), []byte("not a puzzle\n"), 0644)
+	pack := filepath.Join(t.TempDir(), `all.pack.json`)
+	if code, _, stderr := runCmd(``, `convert`, `-o`, pack, in); code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	pk, err := picross.OpenPicrPack(pack)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if len(pk.Entries) != 2 || pk.Get(`horse`) == nil || pk.Get(`dot`) == nil {
+		t.Fatalf(`unexpected pack entries: %v`, pk.Entries)
+	}
+	if code, _, stderr := runCmd(``, `convert`, `-to`, `webpbn`, `-solve`, `-o`, out, pack); code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	names, _ := filepath.Glob(filepath.Join(out, `*`))
+	sort.Strings(names)
+	if len(names) != 2 || filepath.Base(names[0]) != `all-dot.xml` || filepath.Base(names[1]) != `all-horse.xml` {
+		t.Fatalf(`unexpected output files: %v`, names)
+	}
+	p, err := picross.LoadFile(names[1])
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if p.Goal == nil || p.Goal[0][0] != picross.Fill {
+		t.Errorf(`missing goal: %+v`, p)
+	}
+	// A pack is told by its content, whatever its name.
+	renamed := filepath.Join(t.TempDir(), `b.json`)
+	content, _ := os.ReadFile(pack)
+	os.WriteFile(renamed, content, 0644)
+	renamedOut := t.TempDir()
+	if code, _, stderr := runCmd(``, `convert`, `-to`, `webpbn`, `-o`, renamedOut, renamed); code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	if names, _ := filepath.Glob(filepath.Join(renamedOut, `b-*.xml`)); len(names) != 2 {
+		t.Errorf(`unexpected output files: %v`, names)
+	}
+	if code, stdout, stderr := runCmd(``, `hash`, renamed); code != exitSolved || strings.Count(stdout, "\n") != 2 {
+		t.Errorf(`unexpected hash of a renamed pack: %v %q %q`, code, stdout, stderr)
+	}
+	// A directory that does not exist yet is created when asked for with a trailing separator,
+	// or when the puzzles come from a directory, even a single one.
+	single := t.TempDir()
+	os.WriteFile(filepath.Join(single, `horse.tatham`), []byte(horseTatham), 0644)
+	for _, c := range []struct{ out, in string }{
+		{filepath.Join(t.TempDir(), `new`) + string(filepath.Separator), filepath.Join(single, `horse.tatham`)},
+		{filepath.Join(t.TempDir(), `new`), single},
+	} {
+		if code, _, stderr := runCmd(``, `convert`, `-to`, `webpbn`, `-o`, c.out, c.in); code != exitSolved {
+			t.Fatalf(`%v: unexpected exit code: %v %q`, c.out, code, stderr)
+		}
+		if _, err := os.Stat(filepath.Join(c.out, `horse.xml`)); err != nil {
+			t.Errorf(`%v: unexpected error: %v`, c.out, err)
+		}
+	}
+}
+
+func TestConvertFail(t *testing.T) {
+	checks := []struct {
+		stdin string
+		args  []string
+	}{
+		{horseTatham, []string{`convert`}},
+		{horseTatham, []string{`convert`, `-to`, `nonogramsorg`}},
+		{horseTatham, []string{`convert`, `-to`, `bogus`}},
+		{"2x2:1/1/1/1\n", []string{`convert`, `-to`, `text`, `-solve`}},
+		{"garbage\n", []string{`convert`, `-to`, `text`}},
+		{``, []string{`convert`, `-to`, `text`, `/nonexistent`}},
+	}
+	for _, c := range checks {
+		if code, _, _ := runCmd(c.stdin, c.args...); code != exitInvalid {
+			t.Errorf(`%q %v: unexpected exit code %v`, c.stdin, c.args, code)
+		}
+	}
+	in := t.TempDir()
+	os.WriteFile(filepath.Join(in, `a.tatham`), []byte(horseTatham), 0644)
+	os.WriteFile(filepath.Join(in, `b.tatham`), []byte(horseTatham), 0644)
+	if code, _, stderr := runCmd(``, `convert`, `-to`, `text`, in); code != exitInvalid || !strings.Contains(stderr, `output directory`) {
+		t.Errorf(`unexpected result: %v %q`, code, stderr)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["explain"] = command{summary: "point out the clues of a puzzle that contradict each other", run: runExplain}
+}
+
+// explainReport is the JSON report of the explain command.
+type explainReport struct {
+	// Consistent tells that the clues have a solution, leaving Core and Edits empty.
+	Consistent bool                   `json:"consistent"`
+	Core       []picross.PicrLine     `json:"core"`
+	Edits      []picross.PicrClueEdit `json:"edits"`
+}
+
+func runExplain(args []string, env *environ) int {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross explain [flags] [file]\n\n"+
+			"Finds a small set of lines whose clues contradict each other in a puzzle read from file (or stdin),\n"+
+			"and proposes single clue edits that make the puzzle solvable.\n\n")
+		fs.PrintDefaults()
+	}
+	jsonOut := fs.Bool("json", false, "print a JSON report")
+	format := fs.String("format", "", "input format, detected from the content when empty")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return exitInvalid
+	}
+	p, err := loadPuzzle(fs.Arg(0), *format, env)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	e, err := p.Explain()
+	consistent := errors.Is(err, picross.ErrPicrConsistent)
+	if err != nil && !consistent {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitCode(err)
+	}
+	code := exitContradiction
+	if consistent {
+		code = exitSolved
+	}
+	if *jsonOut {
+		r := explainReport{Consistent: consistent, Core: []picross.PicrLine{}, Edits: []picross.PicrClueEdit{}}
+		if !consistent {
+			r.Core, r.Edits = e.Core, e.Edits
+		}
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			fmt.Fprintf(env.stderr, "picross: %v\n", err)
+			return exitInvalid
+		}
+		return code
+	}
+	if consistent {
+		fmt.Fprintf(env.stdout, "clues are consistent\n")
+		return code
+	}
+	fmt.Fprintf(env.stdout, "contradiction among %d clues:\n", len(e.Core))
+	for _, l := range e.Core {
+		fmt.Fprintf(env.stdout, "  %s %d (%s)\n", l.Axis, l.Index, clueText(l.Clue))
+	}
+	if len(e.Edits) == 0 {
+		fmt.Fprintf(env.stdout, "no single clue edit makes the puzzle solvable\n")
+		return exitContradiction
+	}
+	fmt.Fprintf(env.stdout, "possible fixes:\n")
+	for _, edit := range e.Edits {
+		outcome := "several solutions"
+		if edit.Unique {
+			outcome = "unique solution"
+		}
+		fmt.Fprintf(env.stdout, "  %s %d: %s -> %s (%s)\n", edit.Axis, edit.Index, clueText(edit.Clue), clueText(edit.To), outcome)
+	}
+	return exitContradiction
+}
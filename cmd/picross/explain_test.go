@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+// horseTypo is the horse with "2 1" mistyped for the "1 1" clue of its second row.
+const horseTypo = "5x5:1/5/1.2/3/2/3/2.1/4/3/1.1\n"
+
+func TestExplain(t *testing.T) {
+	code, stdout, stderr := runCmd(horseTypo, `explain`)
+	if code != exitContradiction {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	if !strings.HasPrefix(stdout, "contradiction among 6 clues:\n  row 1 (3)\n  row 2 (2 1)\n") ||
+		!strings.Contains(stdout, "  row 2: 2 1 -> 1 1 (unique solution)\n") {
+		t.Errorf(`unexpected output: %q`, stdout)
+	}
+	code, stdout, _ = runCmd(horseTypo, `explain`, `-json`)
+	var e picross.PicrExplanation
+	if err := json.Unmarshal([]byte(stdout), &e); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if code != exitContradiction || len(e.Core) != 6 || len(e.Edits) == 0 || !e.Edits[0].Unique {
+		t.Errorf(`unexpected report: %v %+v`, code, e)
+	}
+	code, stdout, _ = runCmd("2x2:2/0/2/0\n", `explain`)
+	if code != exitContradiction || !strings.HasSuffix(stdout, "no single clue edit makes the puzzle solvable\n") {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+	code, stdout, _ = runCmd(horseTatham, `explain`)
+	if code != exitSolved || stdout != "clues are consistent\n" {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+	code, stdout, _ = runCmd(horseTatham, `explain`, `-json`)
+	var r explainReport
+	if err := json.Unmarshal([]byte(stdout), &r); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if code != exitSolved || !r.Consistent || r.Core == nil || len(r.Core) != 0 || len(r.Edits) != 0 {
+		t.Errorf(`unexpected report: %v %q`, code, stdout)
+	}
+}
+
+func TestExplainFail(t *testing.T) {
+	if code, _, _ := runCmd("garbage\n", `explain`); code != exitInvalid {
+		t.Errorf(`unexpected exit code: %v`, code)
+	}
+	if code, _, _ := runCmd(horseTypo, `explain`, `a`, `b`); code != exitInvalid {
+		t.Errorf(`unexpected exit code: %v`, code)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["generate"] = command{summary: "generate random puzzles with a unique solution", run: runGenerate}
+}
+
+func runGenerate(args []string, env *environ) int {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross generate [flags]\n\nGenerates random puzzles with a unique solution, embedding it as the goal.\n"+
+			"A single puzzle goes to stdout or to the -o file; many puzzles go to the -o directory,\n"+
+			"or into a single pack when writing the pack format.\n\n")
+		fs.PrintDefaults()
+	}
+	width := fs.Uint("width", 10, "amount of columns")
+	height := fs.Uint("height", 10, "amount of rows")
+	density := fs.Float64("density", 0.5, "fraction of filled cells")
+	clustering := fs.Float64("cluster", 0.5, "from 0 (noise) to 1 (blobs), how much filled cells gather")
+	line := fs.Bool("line", false, "keep only puzzles solvable by line logic alone")
+	seed := fs.Int64("seed", 0, "random seed (default from the clock, reported on stderr)")
+	count := fs.Uint("count", 1, "amount of puzzles")
+	attempts := fs.Uint("attempts", 0, "grids tried per puzzle before giving up (0 selects the default)")
+	symmetry := fs.String("symmetry", "identity", "symmetry of the pictures: mirror-h, mirror-v, rotate-180, transpose, rotate-90, ...")
+	connected := fs.Bool("connected", false, "keep only pictures whose filled cells are all joined")
+	maxRuns := fs.Uint("max-runs", 0, "largest amount of runs in a line (0 for no limit)")
+	minFill := fs.Float64("min-fill", 0, "smallest fraction of filled cells")
+	maxFill := fs.Float64("max-fill", 0, "largest fraction of filled cells (0 selects 1)")
+	text := fs.String("text", "", "render a short word or number instead of random cells")
+	title := fs.String("title", "", "title of the puzzles, numbered when many")
+	to := fs.String("to", "text", "output format")
+	out := fs.String("o", "", "output file or directory (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitInvalid
+	}
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	if !given["seed"] {
+		*seed = time.Now().UnixNano()
+		fmt.Fprintf(env.stderr, "picross: seed %d\n", *seed)
+	}
+	opts := picross.PicrGenerateOptions{
+		Width: *width, Height: *height, Density: *density, Clustering: *clustering,
+		LineSolvable: *line, Seed: *seed, MaxAttempts: *attempts,
+		Connected: *connected, MaxRuns: *maxRuns, MinFill: *minFill, MaxFill: *maxFill, Text: *text,
+	}
+	if *text != "" {
+		// Without an explicit size, the grid fits the text.
+		if !given["width"] {
+			opts.Width = 0
+		}
+		if !given["height"] {
+			opts.Height = 0
+		}
+	}
+	if (given["min-fill"] || given["max-fill"]) && !given["density"] {
+		// The density is drawn within the fill band.
+		opts.Density = 0
+	}
+	sym, err := picross.ParsePicrSymmetry(*symmetry)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	opts.Symmetry = sym
+	g, err := picross.NewPicrGenerator(opts)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	puzzles := make([]namedPuzzle, 0, *count)
+	for i := uint(1); i <= *count; i++ {
+		p, err := g.Next()
+		if err != nil {
+			fmt.Fprintf(env.stderr, "picross: puzzle %d: %v\n", i, err)
+			return exitInvalid
+		}
+		p.Title = *title
+		if *title != "" && *count > 1 {
+			p.Title = fmt.Sprintf("%s %d", *title, i)
+		}
+		puzzles = append(puzzles, namedPuzzle{name: fmt.Sprintf("random-%d-%d", *seed, i), puzzle: p})
+	}
+	if err := writePuzzles(puzzles, *to, *out, false, env); err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	return exitSolved
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func TestGenerate(t *testing.T) {
+	args := []string{`generate`, `-width`, `6`, `-height`, `4`, `-seed`, `3`, `-title`, `fresh`}
+	code, stdout, stderr := runCmd(``, args...)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	if stderr != `` {
+		t.Errorf(`unexpected diagnostics: %q`, stderr)
+	}
+	p, err := picross.Load(strings.NewReader(stdout))
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if p.Title != `fresh` || p.Width() != 6 || p.Height() != 4 || p.Goal == nil {
+		t.Errorf(`unexpected puzzle: %+v`, p)
+	}
+	if _, again, _ := runCmd(``, args...); again != stdout {
+		t.Errorf(`puzzle not reproduced: %q`, again)
+	}
+	pack := filepath.Join(t.TempDir(), `fresh.pack.json`)
+	code, _, stderr = runCmd(``, `generate`, `-count`, `3`, `-line`, `-to`, `pack`, `-o`, pack)
+	if code != exitSolved || !strings.HasPrefix(stderr, `picross: seed `) {
+		t.Fatalf(`unexpected result: %v %q`, code, stderr)
+	}
+	pk, err := picross.OpenPicrPack(pack)
+	if err != nil || len(pk.Entries) != 3 {
+		t.Fatalf(`unexpected pack: %v %v`, pk, err)
+	}
+}
+
+func TestGenerateShapes(t *testing.T) {
+	code, stdout, stderr := runCmd(``, `generate`, `-text`, `HI`, `-seed`, `1`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	p, err := picross.Load(strings.NewReader(stdout))
+	if err != nil || p.Width() != 9 || p.Height() != 7 {
+		t.Errorf(`unexpected puzzle: %v %v`, p, err)
+	}
+	code, stdout, stderr = runCmd(``, `generate`, `-width`, `8`, `-height`, `8`, `-symmetry`, `rotate-180`, `-connected`, `-min-fill`, `0.4`, `-max-fill`, `0.6`, `-seed`, `2`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	if p, err = picross.Load(strings.NewReader(stdout)); err != nil || !reflect.DeepEqual(picross.PicrTransformGrid(p.Goal, picross.PicrRotate180), p.Goal) {
+		t.Errorf(`unexpected puzzle: %v %v`, p, err)
+	}
+}
+
+func TestGenerateFail(t *testing.T) {
+	for _, args := range [][]string{
+		{`generate`, `-width`, `0`},
+		{`generate`, `-density`, `2`},
+		{`generate`, `extra`},
+		{`generate`, `-symmetry`, `bogus`},
+		{`generate`, `-symmetry`, `transpose`, `-width`, `3`},
+		{`generate`, `-count`, `2`, `-seed`, `1`},
+		{`generate`, `-width`, `2`, `-height`, `2`, `-cluster`, `0`, `-attempts`, `1`, `-count`, `50`, `-seed`, `1`, `-to`, `pack`},
+	} {
+		if code, _, _ := runCmd(``, args...); code != exitInvalid {
+			t.Errorf(`%v: unexpected exit code: %v`, args, code)
+		}
+	}
+}
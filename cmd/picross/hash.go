@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["hash"] = command{summary: "print content hashes and find duplicate puzzles", run: runHash}
+}
+
+func runHash(args []string, env *environ) int {
+	fs := flag.NewFlagSet("hash", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross hash [flags] [file or directory...]\n\n"+
+			"Prints the content hash of puzzles read from files, directories or packs (or stdin),\n"+
+			"which is the same for puzzles that differ only by mirroring, rotation or transposition.\n"+
+			"With -dups or -known, reports duplicates instead, exiting with %d when any is found.\n\n", exitStalled)
+		fs.PrintDefaults()
+	}
+	dups := fs.Bool("dups", false, "report the groups of duplicate puzzles")
+	known := fs.String("known", "", "report the puzzles already in this pack")
+	format := fs.String("format", "", "input format, detected from the content when empty")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	inputs := fs.Args()
+	if len(inputs) < 1 {
+		inputs = []string{"-"}
+	}
+	var puzzles []namedPuzzle
+	for _, input := range inputs {
+		ps, err := readPuzzles(input, *format, env)
+		if err != nil {
+			fmt.Fprintf(env.stderr, "picross: %v\n", err)
+			return exitInvalid
+		}
+		puzzles = append(puzzles, ps...)
+	}
+	var pk *picross.PicrPack
+	if *known != "" {
+		var err error
+		if pk, err = picross.OpenPicrPack(*known); err != nil {
+			fmt.Fprintf(env.stderr, "picross: %v\n", err)
+			return exitInvalid
+		}
+	}
+	if !*dups && pk == nil {
+		for _, np := range puzzles {
+			fmt.Fprintf(env.stdout, "%s  %s\n", np.puzzle.Hash(), np.name)
+		}
+		return exitSolved
+	}
+	code := exitSolved
+	if *dups {
+		groups := map[string][]string{}
+		var hashes []string
+		for _, np := range puzzles {
+			hash := np.puzzle.Hash()
+			if groups[hash] == nil {
+				hashes = append(hashes, hash)
+			}
+			groups[hash] = append(groups[hash], np.name)
+		}
+		for _, hash := range hashes {
+			if names := groups[hash]; len(names) > 1 {
+				fmt.Fprintf(env.stdout, "duplicates: %s\n", strings.Join(names, ", "))
+				code = exitStalled
+			}
+		}
+	}
+	if pk != nil {
+		for _, np := range puzzles {
+			if e := pk.Lookup(np.puzzle); e != nil {
+				fmt.Fprintf(env.stdout, "known: %s is %s in %s\n", np.name, e.ID, *known)
+				code = exitStalled
+			}
+		}
+	}
+	return code
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+// horseTransposed is horseTatham with rows and columns swapped.
+const horseTransposed = "5x5:3/1.1/4/3/1.1/1/5/1.2/3/2\n"
+
+func TestHash(t *testing.T) {
+	code, stdout, stderr := runCmd(horseTatham, `hash`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	p, _ := picross.Load(strings.NewReader(horseTatham))
+	if stdout != p.Hash()+"  stdin\n" {
+		t.Errorf(`unexpected output: %q`, stdout)
+	}
+	in := t.TempDir()
+	os.WriteFile(filepath.Join(in, `a.tatham`), []byte(horseTatham), 0644)
+	os.WriteFile(filepath.Join(in, `b.tatham`), []byte(horseTransposed), 0644)
+	os.WriteFile(filepath.Join(in, `c.txt`), []byte(ambiguousText), 0644)
+	if code, stdout, _ = runCmd(``, `hash`, in); code != exitSolved || strings.Count(stdout, p.Hash()) != 2 {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+	if code, stdout, _ = runCmd(``, `hash`, `-dups`, in); code != exitStalled || stdout != "duplicates: a, b\n" {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+	pack := filepath.Join(t.TempDir(), `known.pack.json`)
+	if code, _, stderr = runCmd(``, `convert`, `-to`, `pack`, `-o`, pack, in); code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	code, stdout, _ = runCmd(horseTransposed, `hash`, `-known`, pack)
+	if code != exitStalled || stdout != "known: stdin is a in "+pack+"\n" {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+	if code, stdout, _ = runCmd("5x5:5/5/5/5/5/5/5/5/5/5\n", `hash`, `-known`, pack); code != exitSolved || stdout != `` {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+}
+
+func TestHashFail(t *testing.T) {
+	for _, args := range [][]string{
+		{`hash`, `-known`, `/nonexistent/pack.json`},
+		{`hash`, `/nonexistent/puzzle.txt`},
+	} {
+		if code, _, _ := runCmd(horseTatham, args...); code != exitInvalid {
+			t.Errorf(`%v: unexpected exit code: %v`, args, code)
+		}
+	}
+	if code, _, _ := runCmd("garbage\n", `hash`); code != exitInvalid {
+		t.Errorf(`unexpected exit code: %v`, code)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["import"] = command{summary: "turn an image into a uniquely solvable puzzle", run: runImport}
+}
+
+func runImport(args []string, env *environ) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross import [flags] [image]\n\n"+
+			"Reads a PNG, PBM or PGM image from file (or stdin), crops, scales, thresholds and despeckles it\n"+
+			"with every combination of the given scales and thresholds, and writes the uniquely solvable puzzle\n"+
+			"(or solvable by line logic, with -line) that looks closest to the image.\n"+
+			"The chosen parameters and the difficulty rating are reported on stderr.\n\n")
+		fs.PrintDefaults()
+	}
+	width := fs.Uint("width", 0, "amount of columns (0 follows the aspect ratio, or the image)")
+	height := fs.Uint("height", 0, "amount of rows (0 follows the aspect ratio, or the image)")
+	scales := fs.String("scales", "1", "comma separated factors applied to the size")
+	thresholds := fs.String("thresholds", "0.3,0.4,0.5,0.6,0.7", "comma separated luminances, from 0 (black) to 1 (white), tried")
+	crop := fs.String("crop", "", "part of the image to use, as x0,y0,x1,y1 in pixels")
+	autoCrop := fs.Bool("autocrop", false, "trim the light margins of the image")
+	dither := fs.Bool("dither", false, "diffuse the thresholding error")
+	despeckle := fs.Bool("despeckle", true, "remove isolated pixels")
+	line := fs.Bool("line", false, "require the puzzle to be solvable by line logic alone")
+	verbose := fs.Bool("v", false, "report every variant tried")
+	timeout := fs.Duration("timeout", 0, "give up after this long (0 waits forever)")
+	title := fs.String("title", "", "title of the puzzle")
+	to := fs.String("to", "text", "output format")
+	out := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return exitInvalid
+	}
+	opts := picross.PicrPipelineOptions{
+		Width: *width, Height: *height, AutoCrop: *autoCrop,
+		Dither: *dither, Despeckle: *despeckle, LineSolvable: *line,
+	}
+	var err error
+	if opts.Scales, err = parseFloats(*scales); err == nil {
+		if opts.Thresholds, err = parseFloats(*thresholds); err == nil && *crop != "" {
+			opts.Crop, err = parseRect(*crop)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	img, err := readImage(fs.Arg(0), env)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	r, err := runPipelineWithTimeout(img, opts, *timeout)
+	if errors.Is(err, errTimeout) {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitTimeout
+	}
+	if r != nil && *verbose {
+		for _, v := range r.Variants {
+			fmt.Fprintf(env.stderr, "picross: %s\n", variantText(v))
+		}
+	}
+	if errors.Is(err, picross.ErrPicrNoVariant) {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitStalled
+	}
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	v := r.Variants[r.Chosen]
+	fmt.Fprintf(env.stderr, "picross: chosen %s\n", variantText(v))
+	fmt.Fprintf(env.stderr, "picross: rated %s (score %v)\n", r.Rating.Tier, r.Rating.Score)
+	v.Puzzle.Title = *title
+	if err := writePuzzles([]namedPuzzle{{name: "import", puzzle: v.Puzzle}}, *to, *out, false, env); err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	return exitSolved
+}
+
+// runPipelineWithTimeout runs the image pipeline, abandoning it on timeout:
+// line logic alone can take very long over the clues of a noisy picture.
+func runPipelineWithTimeout(img image.Image, opts picross.PicrPipelineOptions, timeout time.Duration) (*picross.PicrPipelineResult, error) {
+	type outcome struct {
+		r   *picross.PicrPipelineResult
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		r, err := picross.RunPicrPipeline(img, opts)
+		done <- outcome{r, err}
+	}()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = time.After(timeout)
+	}
+	select {
+	case o := <-done:
+		return o.r, o.err
+	case <-expired:
+		return nil, fmt.Errorf("%w after %v", errTimeout, timeout)
+	}
+}
+
+// readImage decodes an image from a file, or from stdin when the path is empty or "-".
+func readImage(path string, env *environ) (image.Image, error) {
+	var r io.Reader = env.stdin
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return picross.ReadPicrImage(r)
+}
+
+// variantText describes a variant tried by the image pipeline.
+func variantText(v picross.PicrPipelineVariant) string {
+	status := "not line solvable"
+	switch {
+	case v.LineSolvable:
+		status = "line solvable"
+	case v.Unique:
+		status = "unique"
+	case v.Searched:
+		status = "ambiguous"
+	}
+	return fmt.Sprintf("%dx%d, threshold %v, %d specks removed, resemblance %.1f%%, %s",
+		v.Width, v.Height, v.Threshold, v.Despeckled, 100*v.Resemblance, status)
+}
+
+// parseFloats parses a comma separated list of numbers.
+func parseFloats(s string) ([]float64, error) {
+	var ans []float64
+	for _, field := range strings.Split(s, ",") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || f <= 0 {
+			return nil, fmt.Errorf("invalid number %q", field)
+		}
+		ans = append(ans, f)
+	}
+	return ans, nil
+}
+
+// parseRect parses a rectangle given as x0,y0,x1,y1.
+func parseRect(s string) (image.Rectangle, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 4 {
+		return image.Rectangle{}, fmt.Errorf("invalid rectangle %q", s)
+	}
+	var c [4]int
+	for i, field := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return image.Rectangle{}, fmt.Errorf("invalid rectangle %q", s)
+		}
+		c[i] = n
+	}
+	return image.Rect(c[0], c[1], c[2], c[3]), nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+const horsePbm = "P1 7 7\n0000000\n0111000\n0010010\n0011110\n0011100\n0010100\n0000000\n"
+
+func TestImport(t *testing.T) {
+	code, stdout, stderr := runCmd(horsePbm, `import`, `-autocrop`, `-title`, `horse`, `-v`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	// The ear of the horse is an isolated pixel.
+	if !strings.Contains(stderr, "picross: chosen 5x5, threshold 0.3, 1 specks removed, resemblance 96.0%, line solvable\n") ||
+		!strings.Contains(stderr, "picross: rated Easy") {
+		t.Errorf(`unexpected report: %q`, stderr)
+	}
+	if strings.Count(stderr, "\n") != 7 {
+		t.Errorf(`unexpected amount of variants: %q`, stderr)
+	}
+	p, err := picross.Load(strings.NewReader(stdout))
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if p.Title != `horse` || p.Width() != 5 || p.Height() != 5 {
+		t.Errorf(`unexpected puzzle: %+v`, p)
+	}
+	code, stdout, _ = runCmd(horsePbm, `import`, `-crop`, `1,1,6,6`, `-thresholds`, `0.5`, `-width`, `10`)
+	if p, err = picross.Load(strings.NewReader(stdout)); code != exitSolved || err != nil || p.Width() != 10 {
+		t.Errorf(`unexpected result: %v %v`, code, err)
+	}
+}
+
+func TestImportAmbiguous(t *testing.T) {
+	diagonal := "P1 2 2\n10\n01\n"
+	code, _, stderr := runCmd(diagonal, `import`, `-v`, `-despeckle=false`)
+	if code != exitStalled || !strings.Contains(stderr, ", ambiguous\n") {
+		t.Errorf(`unexpected result: %v %q`, code, stderr)
+	}
+	// Without search, the uniqueness of a variant that line logic leaves unfinished is unknown.
+	code, _, stderr = runCmd(diagonal, `import`, `-v`, `-despeckle=false`, `-line`)
+	if code != exitStalled || strings.Contains(stderr, "ambiguous") || !strings.Contains(stderr, ", not line solvable\n") {
+		t.Errorf(`unexpected result: %v %q`, code, stderr)
+	}
+}
+
+func TestImportFail(t *testing.T) {
+	checks := []struct {
+		stdin string
+		args  []string
+		code  int
+	}{
+		{"P1 2 2\n10\n01\n", []string{`import`}, exitStalled},
+		{horsePbm, []string{`import`, `-crop`, `1,1`}, exitInvalid},
+		{horsePbm, []string{`import`, `-scales`, `x`}, exitInvalid},
+		{horsePbm, []string{`import`, `a`, `b`}, exitInvalid},
+		{horsePbm, []string{`import`, `-timeout`, `1ns`}, exitTimeout},
+		{"garbage\n", []string{`import`}, exitInvalid},
+	}
+	for _, c := range checks {
+		if code, _, stderr := runCmd(c.stdin, c.args...); code != c.code {
+			t.Errorf(`%q %v: unexpected exit code %v: %q`, c.stdin, c.args, code, stderr)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Command picross solves, checks and converts picross (nonogram) puzzles.
+//
+// Usage:
+//
+//	picross <command> [flags] [arguments]
+//
+// Run `picross help` for the list of commands.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Exit codes shared by all commands.
+const (
+	exitSolved        = 0
+	exitContradiction = 1
+	exitStalled       = 2
+	exitInvalid       = 3
+	exitTimeout       = 4
+)
+
+// command is a subcommand of the tool.
+type command struct {
+	summary string
+	run     func(args []string, env *environ) int
+}
+
+// environ holds the standard streams of a command, so that tests can provide their own.
+type environ struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+var commands = map[string]command{}
+
+func main() {
+	os.Exit(run(os.Args[1:], &environ{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+// run dispatches to a subcommand, returning the exit code.
+func run(args []string, env *environ) int {
+	if len(args) < 1 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(env.stderr)
+		if len(args) < 1 {
+			return exitInvalid
+		}
+		return exitSolved
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "picross: unknown command %q\n", args[0])
+		usage(env.stderr)
+		return exitInvalid
+	}
+	return cmd.run(args[1:], env)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: picross <command> [flags] [arguments]\n\ncommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(w, "\nexit codes: %d solved, %d contradictory, %d ambiguous or stalled, %d invalid input, %d timeout\n",
+		exitSolved, exitContradiction, exitStalled, exitInvalid, exitTimeout)
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// runCmd runs the tool with the given arguments and standard input.
+func runCmd(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &environ{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr})
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	if code, _, _ := runCmd(``); code != exitInvalid {
+		t.Errorf(`unexpected exit code: %v`, code)
+	}
+	if code, _, stderr := runCmd(``, `help`); code != exitSolved || !strings.Contains(stderr, `solve`) {
+		t.Errorf(`unexpected help: %v %q`, code, stderr)
+	}
+	if code, _, _ := runCmd(``, `frobnicate`); code != exitInvalid {
+		t.Errorf(`unexpected exit code: %v`, code)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["metrics"] = command{summary: "summarize quality metrics of a corpus as CSV", run: runMetrics}
+}
+
+// metricsColumns is the header of the CSV output of the metrics command.
+var metricsColumns = []string{"puzzle", "title", "width", "height", "density", "clues", "mean_run",
+	"trivial_lines", "empty_lines", "first_round", "rounds", "line_solved", "error"}
+
+func runMetrics(args []string, env *environ) int {
+	fs := flag.NewFlagSet("metrics", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross metrics [flags] [file or directory...]\n\n"+
+			"Measures puzzles read from files, directories or packs (or stdin), writing a CSV row for each.\n"+
+			"With -heatmap, writes instead the round of line logic that determines each cell,\n"+
+			"as 1 to 9 and then letters, '.' for cells left unknown.\n\n")
+		fs.PrintDefaults()
+	}
+	summary := fs.Bool("summary", false, "add a last row with the mean of every column over the corpus")
+	heatmap := fs.Bool("heatmap", false, "write the cell determination order of every puzzle")
+	format := fs.String("format", "", "input format, detected from the content when empty")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	inputs := fs.Args()
+	if len(inputs) < 1 {
+		inputs = []string{"-"}
+	}
+	var puzzles []namedPuzzle
+	for _, input := range inputs {
+		ps, err := readPuzzles(input, *format, env)
+		if err != nil {
+			fmt.Fprintf(env.stderr, "picross: %v\n", err)
+			return exitInvalid
+		}
+		puzzles = append(puzzles, ps...)
+	}
+	code := exitSolved
+	w := csv.NewWriter(env.stdout)
+	if !*heatmap {
+		w.Write(metricsColumns)
+	}
+	var measured []*picross.PicrMetrics
+	for _, np := range puzzles {
+		m, err := np.puzzle.Metrics()
+		if err != nil {
+			code = exitInvalid
+			if *heatmap {
+				fmt.Fprintf(env.stderr, "picross: %s: %v\n", np.name, err)
+			} else {
+				w.Write([]string{np.name, np.puzzle.Title, "", "", "", "", "", "", "", "", "", "", err.Error()})
+			}
+			continue
+		}
+		if *heatmap {
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s\n", np.name)
+			for _, row := range m.Order {
+				fmt.Fprintf(&b, "  %s\n", heatmapLine(row))
+			}
+			if _, err := io.WriteString(env.stdout, b.String()); err != nil {
+				fmt.Fprintf(env.stderr, "picross: %v\n", err)
+				return exitInvalid
+			}
+			continue
+		}
+		measured = append(measured, m)
+		w.Write(append([]string{np.name, np.puzzle.Title}, metricsFields(*m)...))
+	}
+	if *summary && !*heatmap && len(measured) > 0 {
+		w.Write(append([]string{"mean", ""}, meanFields(measured)...))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	return code
+}
+
+// metricsFields formats the metrics of a puzzle as CSV fields, after the name and the title.
+func metricsFields(m picross.PicrMetrics) []string {
+	return []string{
+		strconv.FormatUint(uint64(m.Width), 10), strconv.FormatUint(uint64(m.Height), 10),
+		formatMetric(m.Density), strconv.FormatUint(uint64(m.Clues), 10), formatMetric(m.MeanRun),
+		strconv.FormatUint(uint64(m.TrivialLines), 10), strconv.FormatUint(uint64(m.EmptyLines), 10),
+		strconv.FormatUint(uint64(m.FirstRound), 10), strconv.FormatUint(uint64(m.Rounds), 10),
+		strconv.FormatBool(m.LineSolved), "",
+	}
+}
+
+// meanFields averages metrics over a corpus as CSV fields, after the name and the title.
+// The line_solved field becomes the fraction of puzzles that line logic solves.
+func meanFields(ms []*picross.PicrMetrics) []string {
+	var sum [10]float64
+	for _, m := range ms {
+		solved := 0.0
+		if m.LineSolved {
+			solved = 1
+		}
+		for k, v := range []float64{float64(m.Width), float64(m.Height), m.Density, float64(m.Clues), m.MeanRun,
+			float64(m.TrivialLines), float64(m.EmptyLines), float64(m.FirstRound), float64(m.Rounds), solved} {
+			sum[k] += v
+		}
+	}
+	ans := make([]string, 0, len(sum)+1)
+	for _, v := range sum {
+		ans = append(ans, formatMetric(v/float64(len(ms))))
+	}
+	return append(ans, "")
+}
+
+// formatMetric writes a fractional metric with a few decimals.
+func formatMetric(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+// heatmapLine draws the rounds that determined the cells of a row.
+func heatmapLine(row []uint) string {
+	const digits = ".123456789abcdefghijklmnopqrstuvwxyz"
+	ans := make([]byte, len(row))
+	for j, v := range row {
+		if v >= uint(len(digits)) {
+			v = uint(len(digits)) - 1
+		}
+		ans[j] = digits[v]
+	}
+	return string(ans)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	code, stdout, stderr := runCmd(horseTatham, `metrics`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	expected := "puzzle,title,width,height,density,clues,mean_run,trivial_lines,empty_lines,first_round,rounds,line_solved,error\n" +
+		"stdin,,5,5,0.560,13,2.154,1,0,15,3,true,\n"
+	if stdout != expected {
+		t.Errorf(`unexpected output: %q`, stdout)
+	}
+	in := t.TempDir()
+	os.WriteFile(filepath.Join(in, `a.tatham`), []byte(horseTatham), 0644)
+	os.WriteFile(filepath.Join(in, `b.tatham`), []byte("2x1:1/0/1\n"), 0644)
+	code, stdout, _ = runCmd(``, `metrics`, `-summary`, in)
+	lines := strings.Split(stdout, "\n")
+	if code != exitSolved || len(lines) != 5 || lines[3] != "mean,,3.500,3.000,0.530,7.500,1.577,1.000,0.500,8.500,2.000,1.000," {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+	code, stdout, _ = runCmd(horseTatham, `metrics`, `-heatmap`)
+	if code != exitSolved || stdout != "stdin\n  31131\n  11122\n  21112\n  31131\n  11122\n" {
+		t.Errorf(`unexpected heatmap: %v %q`, code, stdout)
+	}
+}
+
+func TestMetricsFail(t *testing.T) {
+	code, stdout, _ := runCmd("2x2:2/0/2/0\n", `metrics`)
+	if code != exitInvalid || !strings.HasPrefix(strings.Split(stdout, "\n")[1], "stdin,,,,,,,,,,,,PicrWorker") {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+	if code, _, _ := runCmd(``, `metrics`, `/nonexistent/puzzle.txt`); code != exitInvalid {
+		t.Errorf(`unexpected exit code: %v`, code)
+	}
+	// A broken output stream is reported.
+	for _, args := range [][]string{{`metrics`}, {`metrics`, `-heatmap`}} {
+		var stderr bytes.Buffer
+		code := run(args, &environ{stdin: strings.NewReader(horseTatham), stdout: brokenWriter{}, stderr: &stderr})
+		if code != exitInvalid || !strings.Contains(stderr.String(), `broken pipe`) {
+			t.Errorf(`%v: unexpected result: %v %q`, args, code, stderr.String())
+		}
+	}
+}
+
+// brokenWriter fails every write.
+type brokenWriter struct{}
+
+func (brokenWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["play"] = command{summary: "play a puzzle in the terminal", run: runPlay}
+}
+
+const playKeys = "arrows/hjkl move, space fill, x cross, c clear, F/X/C drag, ? check lines, u undo, q quit"
+
+// ANSI sequences of the player screen.
+const (
+	ansiClear      = "\x1b[H\x1b[2J"
+	ansiAltScreen  = "\x1b[?1049h\x1b[?25l"
+	ansiMainScreen = "\x1b[?25h\x1b[?1049l"
+	ansiReset      = "\x1b[0m"
+	ansiDim        = "\x1b[2m"
+	ansiBold       = "\x1b[1m"
+	ansiCursor     = "\x1b[30;43m"
+	ansiHint       = "\x1b[32m"
+)
+
+// player is the state of the play command between key presses.
+type player struct {
+	game     *picross.PicrGame
+	row, col int
+	// dragging tells that moves paint the cells they reach with pen, undone as a single group.
+	dragging bool
+	pen      picross.CellState
+	group    uint
+	// hints holds the cells that line logic determined on the last check, by position.
+	hints map[[2]int]picross.CellState
+	msg   string
+	won   bool
+}
+
+func runPlay(args []string, env *environ) int {
+	fs := flag.NewFlagSet("play", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross play [flags] file\n\nPlays a puzzle in the terminal; keys: %s.\nQuitting exits with %d, whether the puzzle was solved or not.\n\n", playKeys, exitSolved)
+		fs.PrintDefaults()
+	}
+	format := fs.String("format", "", "input format, detected from the content when empty")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() != 1 || fs.Arg(0) == "-" {
+		fs.Usage()
+		return exitInvalid
+	}
+	p, err := loadPuzzle(fs.Arg(0), *format, env)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	game, err := picross.NewPicrGame(p)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	if f, ok := env.stdin.(*os.File); ok {
+		restore, err := makeRaw(f)
+		if err == nil {
+			defer restore()
+		}
+	}
+	fmt.Fprint(env.stdout, ansiAltScreen)
+	defer fmt.Fprint(env.stdout, ansiMainScreen)
+	pl := &player{game: game}
+	in := bufio.NewReader(env.stdin)
+	for {
+		io.WriteString(env.stdout, pl.render())
+		key, err := readKey(in)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Fprintf(env.stderr, "picross: %v\n", err)
+			return exitInvalid
+		}
+		if !pl.handle(key) {
+			break
+		}
+	}
+	// No solver ran: leaving the game, solved or not, is not a failure.
+	return exitSolved
+}
+
+// makeRaw puts a terminal in raw mode with stty, returning the function that restores it.
+// It fails when the file is not a terminal.
+func makeRaw(f *os.File) (func(), error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode()&os.ModeCharDevice == 0 {
+		return nil, errors.New("not a terminal")
+	}
+	stty := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = f
+		return cmd.Output()
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(strings.TrimSpace(string(saved))) }, nil
+}
+
+// readKey reads a key press, naming the arrow keys "up", "down", "left" and "right".
+func readKey(in *bufio.Reader) (string, error) {
+	b, err := in.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if b != 0x1b || in.Buffered() < 2 {
+		return string(b), nil
+	}
+	if next, _ := in.Peek(1); next[0] != '[' && next[0] != 'O' {
+		return string(b), nil
+	}
+	in.ReadByte()
+	b, _ = in.ReadByte()
+	switch b {
+	case 'A':
+		return "up", nil
+	case 'B':
+		return "down", nil
+	case 'C':
+		return "right", nil
+	case 'D':
+		return "left", nil
+	}
+	return "", nil
+}
+
+// handle applies a key press, returning false when the player quits.
+func (pl *player) handle(key string) bool {
+	moves := map[string][2]int{
+		"up": {-1, 0}, "k": {-1, 0}, "down": {1, 0}, "j": {1, 0},
+		"left": {0, -1}, "h": {0, -1}, "right": {0, 1}, "l": {0, 1},
+	}
+	pens := map[string]picross.CellState{"F": picross.Fill, "X": picross.Gap, "C": picross.Any}
+	if d, ok := moves[key]; ok {
+		pl.row = clampInt(pl.row+d[0], len(pl.game.Grid)-1)
+		pl.col = clampInt(pl.col+d[1], len(pl.game.Grid[0])-1)
+		if pl.dragging {
+			pl.mark(pl.pen, pl.group)
+		}
+		return true
+	}
+	if pen, ok := pens[key]; ok {
+		if pl.dragging && pl.pen == pen {
+			pl.dragging, pl.msg = false, ""
+			return true
+		}
+		pl.group += 1
+		pl.dragging, pl.pen = true, pen
+		pl.msg = fmt.Sprintf("dragging to %s, press %s or enter to stop", map[picross.CellState]string{picross.Fill: "fill", picross.Gap: "cross", picross.Any: "clear"}[pen], key)
+		pl.mark(pen, pl.group)
+		return true
+	}
+	switch key {
+	case "q", "\x03":
+		return false
+	case " ", "f":
+		pl.toggle(picross.Fill)
+	case "x":
+		pl.toggle(picross.Gap)
+	case "c", "\x7f", "\b":
+		pl.mark(picross.Any, 0)
+	case "\r", "\n":
+		pl.dragging, pl.msg = false, ""
+	case "u":
+		if pl.game.Undo() {
+			pl.hints = nil
+			pl.won = pl.game.Solved()
+		}
+	case "?":
+		pl.check()
+	}
+	return true
+}
+
+// clampInt limits `v` to the range from 0 to `hi`.
+func clampInt(v int, hi int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// toggle marks the cell under the cursor, or clears it if it already has that mark.
+func (pl *player) toggle(v picross.CellState) {
+	if pl.game.Grid[pl.row][pl.col] == v {
+		v = picross.Any
+	}
+	pl.mark(v, 0)
+}
+
+// mark sets the cell under the cursor and checks for a win.
+func (pl *player) mark(v picross.CellState, group uint) {
+	if !pl.game.Set(pl.row, pl.col, v, group) {
+		return
+	}
+	pl.hints = nil
+	pl.won = pl.game.Solved()
+	if pl.won {
+		pl.msg = "Solved!"
+	} else if !pl.dragging {
+		pl.msg = ""
+	}
+}
+
+// check applies line logic to the row and the column under the cursor,
+// reporting contradictions and remembering the cells that could be marked.
+func (pl *player) check() {
+	pl.hints = map[[2]int]picross.CellState{}
+	var msgs []string
+	report := func(name string, hint []picross.CellState, err error, pos func(k int) [2]int) {
+		if err != nil {
+			msgs = append(msgs, name+" contradicts its clue")
+			return
+		}
+		n := 0
+		for k, v := range hint {
+			at := pos(k)
+			if v != picross.Any && pl.game.Grid[at[0]][at[1]] == picross.Any {
+				pl.hints[at] = v
+				n += 1
+			}
+		}
+		msgs = append(msgs, fmt.Sprintf("%s: line logic tells %d more", name, n))
+	}
+	hint, err := pl.game.HintRow(pl.row)
+	report(fmt.Sprintf("row %d", pl.row+1), hint, err, func(k int) [2]int { return [2]int{pl.row, k} })
+	hint, err = pl.game.HintCol(pl.col)
+	report(fmt.Sprintf("column %d", pl.col+1), hint, err, func(k int) [2]int { return [2]int{k, pl.col} })
+	pl.msg = strings.Join(msgs, "; ")
+}
+
+// render draws the whole screen: title, clues, grid, message and keys.
+func (pl *player) render() string {
+	p := pl.game.Puzzle
+	var b strings.Builder
+	b.WriteString(ansiClear)
+	if p.Title != "" {
+		b.WriteString(ansiBold + p.Title + ansiReset + "\r\n")
+	}
+	rowClues := make([]string, len(p.RowClues))
+	margin := 0
+	for i, clue := range p.RowClues {
+		rowClues[i] = clueText(clue)
+		if len(rowClues[i]) > margin {
+			margin = len(rowClues[i])
+		}
+	}
+	// Cells are as wide as the widest column clue number, plus a space to tell numbers apart.
+	depth, width := 1, 2
+	for _, clue := range p.ColClues {
+		if n := len(nonZero(clue)); n > depth {
+			depth = n
+		}
+		for _, v := range clue {
+			if n := len(fmt.Sprint(v)) + 1; n > width {
+				width = n
+			}
+		}
+	}
+	pad := strings.Repeat(" ", width-2)
+	for k := 0; k < depth; k++ {
+		b.WriteString(strings.Repeat(" ", margin+1))
+		for j, clue := range p.ColClues {
+			clue = nonZero(clue)
+			if len(clue) == 0 && k == depth-1 {
+				clue = []uint{0}
+			}
+			cell := strings.Repeat(" ", width)
+			if at := k - (depth - len(clue)); at >= 0 {
+				cell = fmt.Sprintf("%*d", width, clue[at])
+			}
+			if pl.game.ColDone(j) {
+				cell = ansiDim + cell + ansiReset
+			}
+			b.WriteString(cell)
+		}
+		b.WriteString("\r\n")
+	}
+	for i, row := range pl.game.Grid {
+		clue := fmt.Sprintf("%*s ", margin, rowClues[i])
+		if pl.game.RowDone(i) {
+			clue = ansiDim + clue + ansiReset
+		}
+		b.WriteString(clue)
+		for j, v := range row {
+			cell := pad + map[picross.CellState]string{picross.Any: " .", picross.Gap: " x", picross.Fill: "##"}[v]
+			if h, ok := pl.hints[[2]int{i, j}]; ok {
+				cell = pad + ansiHint + map[picross.CellState]string{picross.Gap: " -", picross.Fill: " +"}[h] + ansiReset
+			}
+			if i == pl.row && j == pl.col {
+				cell = ansiCursor + cell + ansiReset
+			}
+			b.WriteString(cell)
+		}
+		b.WriteString("\r\n")
+	}
+	b.WriteString("\r\n" + pl.msg + "\r\n" + ansiDim + playKeys + ansiReset + "\r\n")
+	return b.String()
+}
+
+// clueText writes a clue as space separated run lengths, "0" for an empty line.
+func clueText(clue []uint) string {
+	clue = nonZero(clue)
+	if len(clue) == 0 {
+		return "0"
+	}
+	fields := make([]string, len(clue))
+	for i, v := range clue {
+		fields[i] = fmt.Sprint(v)
+	}
+	return strings.Join(fields, " ")
+}
+
+// nonZero returns the runs of a clue, leaving out zeros.
+func nonZero(clue []uint) []uint {
+	ans := make([]uint, 0, len(clue))
+	for _, v := range clue {
+		if v > 0 {
+			ans = append(ans, v)
+		}
+	}
+	return ans
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func TestReadKey(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader("a\x1b[A\x1b[D\x1bOB\x1b"))
+	for _, expected := range []string{`a`, `up`, `left`, `down`, "\x1b"} {
+		if key, err := readKey(in); err != nil || key != expected {
+			t.Errorf(`expected key %q, got %q %v`, expected, key, err)
+		}
+	}
+	if _, err := readKey(in); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `horse.tatham`)
+	os.WriteFile(path, []byte(horseTatham), 0644)
+	// Fill the second column by dragging, then the remaining cells one by one.
+	keys := "lF" + strings.Repeat("j", 4) + "F" +
+		"kkkkh ll jll j h h j l j q"
+	code, stdout, _ := runCmd(keys, `play`, path)
+	if code != exitSolved {
+		t.Errorf(`unexpected exit code: %v`, code)
+	}
+	frames := strings.Split(stdout, ansiClear)
+	if !strings.Contains(frames[len(frames)-1], `Solved!`) {
+		t.Errorf(`unexpected last frame: %q`, frames[len(frames)-1])
+	}
+	// Quitting an unsolved game is not a failure.
+	code, stdout, _ = runCmd("ll?q", `play`, path)
+	if code != exitSolved || !strings.Contains(stdout, `row 1: line logic tells 1 more; column 3: line logic tells 1 more`) {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+	for _, args := range [][]string{{`play`}, {`play`, `-`}, {`play`, `/nonexistent`}} {
+		if code, _, _ := runCmd(``, args...); code != exitInvalid {
+			t.Errorf(`%v: unexpected exit code: %v`, args, code)
+		}
+	}
+}
+
+func TestPlayerWideClues(t *testing.T) {
+	rows := make([][]uint, 100)
+	for i := range rows {
+		rows[i] = []uint{1}
+	}
+	p, _ := picross.NewPicrPuzzle(rows, [][]uint{{100}, {0}})
+	game, _ := picross.NewPicrGame(p)
+	pl := &player{game: game}
+	if screen := pl.render(); !strings.Contains(screen, ansiClear+"   100") || !strings.Contains(screen, "\r\n1    .   .\r\n") {
+		t.Errorf(`unexpected screen: %q`, screen)
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	p, _ := picross.NewPicrPuzzle([][]uint{{3}, {1, 1}, {4}, {3}, {1, 1}}, [][]uint{{1}, {5}, {1, 2}, {3}, {2}})
+	p.Title = `horse`
+	game, _ := picross.NewPicrGame(p)
+	pl := &player{game: game}
+	for _, key := range []string{`right`, `?`} {
+		pl.handle(key)
+	}
+	if pl.msg != `row 1: line logic tells 1 more; column 2: line logic tells 5 more` || len(pl.hints) != 6 {
+		t.Errorf(`unexpected check: %q %v`, pl.msg, pl.hints)
+	}
+	if screen := pl.render(); !strings.Contains(screen, ansiHint+` +`) || !strings.Contains(screen, "horse") {
+		t.Errorf(`unexpected screen: %q`, screen)
+	}
+	for _, key := range []string{`X`, `down`, `down`, `X`, `down`} {
+		pl.handle(key)
+	}
+	if pl.dragging || game.Grid[0][1] != picross.Gap || game.Grid[2][1] != picross.Gap || game.Grid[3][1] != picross.Any {
+		t.Errorf(`unexpected drag: %v`, game.Grid)
+	}
+	pl.handle(`?`)
+	if !strings.HasPrefix(pl.msg, `row 4: `) || !strings.HasSuffix(pl.msg, `column 2 contradicts its clue`) {
+		t.Errorf(`unexpected check: %q`, pl.msg)
+	}
+	pl.handle(`u`)
+	if game.Grid[0][1] != picross.Any || game.Grid[2][1] != picross.Any {
+		t.Errorf(`unexpected undo: %v`, game.Grid)
+	}
+	for _, key := range []string{`x`, `x`, `f`, `c`} {
+		pl.handle(key)
+	}
+	if game.Grid[3][1] != picross.Any || pl.won {
+		t.Errorf(`unexpected marks: %v`, game.Grid)
+	}
+	if pl.handle(`q`) {
+		t.Errorf(`unexpected continuation`)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["rate"] = command{summary: "rate the difficulty of puzzles", run: runRate}
+}
+
+// rateReport is the JSON output of the rate command for a puzzle.
+type rateReport struct {
+	File   string              `json:"file"`
+	Title  string              `json:"title,omitempty"`
+	Rating *picross.PicrRating `json:"rating,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+func runRate(args []string, env *environ) int {
+	fs := flag.NewFlagSet("rate", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross rate [flags] [file...]\n\nRates puzzles read from files (or stdin) as %s, %s, %s or %s,\nfrom the effort that line logic, probing and search take to solve them.\nThe exit code is the worst among the puzzles.\n\n",
+			picross.PicrTierEasy, picross.PicrTierMedium, picross.PicrTierHard, picross.PicrTierExpert)
+		fs.PrintDefaults()
+	}
+	jsonOut := fs.Bool("json", false, "print a JSON report")
+	format := fs.String("format", "", "input format, detected from the content when empty")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	files := fs.Args()
+	if len(files) < 1 {
+		files = []string{"-"}
+	}
+	reports := make([]rateReport, 0, len(files))
+	worst := exitSolved
+	for _, file := range files {
+		report := rateReport{File: file}
+		code := exitSolved
+		p, err := loadPuzzle(file, *format, env)
+		if err == nil {
+			report.Title = p.Title
+			report.Rating, err = p.Rate()
+		}
+		if err != nil {
+			report.Error = err.Error()
+			code = rateExitCode(err)
+		}
+		if code > worst {
+			worst = code
+		}
+		reports = append(reports, report)
+	}
+	if *jsonOut {
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(reports)
+		return worst
+	}
+	for _, report := range reports {
+		printRateReport(env, report)
+	}
+	return worst
+}
+
+// rateExitCode maps an error of the rater to an exit code.
+func rateExitCode(err error) int {
+	if errors.Is(err, picross.ErrPicrAmbiguous) {
+		return exitStalled
+	}
+	if code := exitCode(err); code != exitStalled {
+		return code
+	}
+	return exitInvalid
+}
+
+// printRateReport writes the rating of a puzzle for humans.
+func printRateReport(env *environ, report rateReport) {
+	if report.Error != "" {
+		fmt.Fprintf(env.stderr, "picross: %s: %s\n", report.File, report.Error)
+		return
+	}
+	r := report.Rating
+	fmt.Fprintf(env.stdout, "%s: %s (score %v)\n", report.File, r.Tier, r.Score)
+	fmt.Fprintf(env.stdout, "  line logic: %d rounds, %d cells, %d deductions yielding %.1f cells on average\n",
+		r.Rounds, r.LineCells, r.Deductions, r.MeanYield)
+	for _, l := range r.Hardest {
+		fmt.Fprintf(env.stdout, "  hard deduction: %s %d (%s) in round %d, %d of %d unknown cells\n",
+			l.Axis, l.Index, clueText(l.Clue), l.Round, l.Yield, l.Unknown)
+	}
+	if r.Probing {
+		fmt.Fprintf(env.stdout, "  probing: %d probes, %d cells forced\n", r.Probes, r.Forced)
+	}
+	if r.Search {
+		fmt.Fprintf(env.stdout, "  search: %d guesses, %d backtracks, depth %d\n", r.Guesses, r.Backtracks, r.MaxDepth)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRate(t *testing.T) {
+	code, stdout, _ := runCmd(horseTatham, `rate`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v`, code)
+	}
+	if !strings.HasPrefix(stdout, "-: Easy (score 11.7)\n  line logic: 3 rounds, 25 cells, 15 deductions") ||
+		!strings.Contains(stdout, "  hard deduction: col 3 (1 2) in round 1, 1 of 5 unknown cells\n") {
+		t.Errorf(`unexpected output: %q`, stdout)
+	}
+	dir := t.TempDir()
+	probe := filepath.Join(dir, `probe.tatham`)
+	os.WriteFile(probe, []byte("5x7:1.2/1.1.2/2/1.1.1/2/4/1/1/1.1/1/2.1/2\n"), 0644)
+	ambiguous := filepath.Join(dir, `ambiguous.tatham`)
+	os.WriteFile(ambiguous, []byte("2x2:1/1/1/1\n"), 0644)
+	code, stdout, _ = runCmd(``, `rate`, `--json`, probe, ambiguous)
+	if code != exitStalled {
+		t.Errorf(`unexpected exit code: %v`, code)
+	}
+	var reports []rateReport
+	if err := json.Unmarshal([]byte(stdout), &reports); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if len(reports) != 2 || reports[0].Rating == nil || !reports[0].Rating.Probing || reports[1].Rating != nil || reports[1].Error == `` {
+		t.Errorf(`unexpected reports: %+v`, reports)
+	}
+	code, stdout, _ = runCmd(``, `rate`, probe)
+	if code != exitSolved || !strings.Contains(stdout, "  probing: ") {
+		t.Errorf(`unexpected output: %v %q`, code, stdout)
+	}
+}
+
+func TestRateFail(t *testing.T) {
+	checks := []struct {
+		stdin string
+		code  int
+	}{
+		{"2x2:1/1/1/1\n", exitStalled},
+		{"2x2:2/2/1/2\n", exitContradiction},
+		{"garbage\n", exitInvalid},
+	}
+	for _, c := range checks {
+		if code, _, stderr := runCmd(c.stdin, `rate`); code != c.code || !strings.HasPrefix(stderr, `picross: -: `) {
+			t.Errorf(`%q: unexpected result: %v %q`, c.stdin, code, stderr)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["repair"] = command{summary: "propose givens or pixel flips that make a design unique", run: runRepair}
+}
+
+// repairReport is the JSON output of the repair command.
+type repairReport struct {
+	Title string `json:"title,omitempty"`
+	// Givens are cells to reveal at the start, and GivensPicture marks them on the goal: 'G' filled, 'g' gap.
+	Givens        []picross.PicrCell `json:"givens"`
+	GivensPicture []string           `json:"givens_picture,omitempty"`
+	GivensError   string             `json:"givens_error,omitempty"`
+	// Flips are cells of the goal to toggle, and FlipsPicture marks them on the new goal: '+' filled, '-' emptied.
+	Flips        []picross.PicrCell `json:"flips"`
+	FlipsPicture []string           `json:"flips_picture,omitempty"`
+	FlipsError   string             `json:"flips_error,omitempty"`
+}
+
+func runRepair(args []string, env *environ) int {
+	fs := flag.NewFlagSet("repair", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross repair [flags] [file]\n\n"+
+			"Reads a puzzle with a goal picture from file (or stdin) and, when its solution is not unique\n"+
+			"(or not reached by line logic, with -line), proposes a few cells to reveal as givens\n"+
+			"and a few cells of the picture to toggle, each fix verified by the solver.\n\n")
+		fs.PrintDefaults()
+	}
+	jsonOut := fs.Bool("json", false, "print a JSON report")
+	line := fs.Bool("line", false, "require the puzzle to be solvable by line logic alone")
+	maxCells := fs.Uint("max", 0, "largest amount of givens or flips proposed (0 selects the default)")
+	mode := fs.String("mode", "both", "fixes to propose: givens, flips or both")
+	out := fs.String("o", "", "write the puzzle with the flips applied to this file")
+	to := fs.String("to", "", "format of the -o file, chosen from its name when empty")
+	format := fs.String("format", "", "input format, detected from the content when empty")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() > 1 || (*mode != "givens" && *mode != "flips" && *mode != "both") {
+		fs.Usage()
+		return exitInvalid
+	}
+	p, err := loadPuzzle(fs.Arg(0), *format, env)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	if p.Goal == nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", picross.ErrPicrNoGoal)
+		return exitInvalid
+	}
+	opts := picross.PicrRepairOptions{LineSolvable: *line, MaxCells: *maxCells}
+	report := repairReport{Title: p.Title}
+	code := exitSolved
+	fail := func(err error) string {
+		if errors.Is(err, picross.ErrPicrNoRepair) {
+			code = maxInt(code, exitStalled)
+		} else {
+			code = exitInvalid
+		}
+		return err.Error()
+	}
+	if *mode != "flips" {
+		if report.Givens, err = p.SuggestGivens(opts); err != nil {
+			report.GivensError = fail(err)
+		} else {
+			report.GivensPicture = markedPicture(p.Goal, report.Givens, "g", "G")
+		}
+	}
+	if *mode != "givens" {
+		var q *picross.PicrPuzzle
+		if report.Flips, q, err = p.SuggestFlips(opts); err != nil {
+			report.FlipsError = fail(err)
+		} else {
+			report.FlipsPicture = markedPicture(q.Goal, report.Flips, "-", "+")
+			if *out != "" {
+				if err := picross.SaveFile(*out, q, *to); err != nil {
+					fmt.Fprintf(env.stderr, "picross: %v\n", err)
+					return exitInvalid
+				}
+			}
+		}
+	}
+	if *jsonOut {
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		return code
+	}
+	printRepairFixes(env, "givens", "reveal these cells at the start", report.Givens, report.GivensPicture, report.GivensError)
+	printRepairFixes(env, "flips", "toggle these cells of the picture", report.Flips, report.FlipsPicture, report.FlipsError)
+	return code
+}
+
+// maxInt returns the largest of two integers.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// markedPicture draws a goal with some cells marked by their value.
+func markedPicture(goal [][]picross.CellState, cells []picross.PicrCell, gap, fill string) []string {
+	ans := gridLines(goal)
+	for _, c := range cells {
+		line := []byte(ans[c.Row-1])
+		line[c.Col-1] = gap[0]
+		if c.Value == picross.Fill {
+			line[c.Col-1] = fill[0]
+		}
+		ans[c.Row-1] = string(line)
+	}
+	return ans
+}
+
+// printRepairFixes writes a kind of fix for humans.
+func printRepairFixes(env *environ, kind string, what string, cells []picross.PicrCell, picture []string, errMsg string) {
+	if errMsg != "" {
+		fmt.Fprintf(env.stderr, "picross: %s: %s\n", kind, errMsg)
+		return
+	}
+	if picture == nil {
+		return
+	}
+	if len(cells) == 0 {
+		fmt.Fprintf(env.stdout, "%s: none needed\n", kind)
+		return
+	}
+	fmt.Fprintf(env.stdout, "%s (%d): %s\n", kind, len(cells), what)
+	for _, c := range cells {
+		fmt.Fprintf(env.stdout, "  row %d, column %d: %s\n", c.Row, c.Col, map[picross.CellState]string{picross.Fill: "fill", picross.Gap: "gap"}[c.Value])
+	}
+	for _, line := range picture {
+		fmt.Fprintf(env.stdout, "  %s\n", line)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+const ambiguousText = "title: ambiguous\nrows: 2 | 1 1 | 2 | 0\ncols: 2 | 1 1 | 1 | 1\n\n##..\n#..#\n.##.\n....\n"
+
+func TestRepair(t *testing.T) {
+	code, stdout, stderr := runCmd(ambiguousText, `repair`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v %q`, code, stderr)
+	}
+	expected := "givens (1): reveal these cells at the start\n  row 1, column 1: fill\n  G#..\n  #..#\n  .##.\n  ....\n" +
+		"flips (1): toggle these cells of the picture\n  row 1, column 1: gap\n  -#..\n  #..#\n  .##.\n  ....\n"
+	if stdout != expected {
+		t.Errorf(`unexpected output: %q`, stdout)
+	}
+	path := filepath.Join(t.TempDir(), `fixed.txt`)
+	code, stdout, _ = runCmd(ambiguousText, `repair`, `-json`, `-mode`, `flips`, `-o`, path)
+	var report repairReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if code != exitSolved || report.Givens != nil || len(report.Flips) != 1 || report.Title != `ambiguous` {
+		t.Errorf(`unexpected report: %v %+v`, code, report)
+	}
+	if !strings.Contains(stdout, `"value": "gap"`) || report.Flips[0].Value != picross.Gap {
+		t.Errorf(`cell value not spelled out: %q`, stdout)
+	}
+	p, err := picross.LoadFile(path)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if r, _ := p.Search(2); len(r.Solutions) != 1 {
+		t.Errorf(`repaired puzzle not unique: %v`, p.Goal)
+	}
+	code, stdout, _ = runCmd("####.\n..#..\n...#.\n.#..#\n....#\n##.#.\n##...\n", `repair`, `-mode`, `givens`)
+	if code != exitSolved || stdout != "givens: none needed\n" {
+		t.Errorf(`unexpected result: %v %q`, code, stdout)
+	}
+}
+
+func TestRepairFail(t *testing.T) {
+	checks := []struct {
+		stdin string
+		args  []string
+		code  int
+	}{
+		{horseTatham, []string{`repair`}, exitInvalid},
+		{ambiguousText, []string{`repair`, `-mode`, `bogus`}, exitInvalid},
+		{ambiguousText, []string{`repair`, `a`, `b`}, exitInvalid},
+		{"garbage\n", []string{`repair`}, exitInvalid},
+		{"#..#.#..#.\n.#.#..#.#.\n..........\n#.........\n.#........\n", []string{`repair`, `-max`, `1`}, exitStalled},
+	}
+	for _, c := range checks {
+		if code, _, stderr := runCmd(c.stdin, c.args...); code != c.code {
+			t.Errorf(`%q %v: unexpected exit code %v: %q`, c.stdin, c.args, code, stderr)
+		}
+	}
+	if _, _, stderr := runCmd(horseTatham, `repair`); !strings.Contains(stderr, `no goal`) {
+		t.Errorf(`unexpected diagnostics: %q`, stderr)
+	}
+}
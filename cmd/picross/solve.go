@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["solve"] = command{summary: "solve a puzzle by line logic and print the grid", run: runSolve}
+}
+
+// solveReport is the JSON output of the solve command.
+type solveReport struct {
+	Status  string   `json:"status"`
+	Title   string   `json:"title,omitempty"`
+	Width   uint     `json:"width,omitempty"`
+	Height  uint     `json:"height,omitempty"`
+	Grid    []string `json:"grid,omitempty"`
+	Unknown int      `json:"unknown"`
+	Error   string   `json:"error,omitempty"`
+}
+
+func runSolve(args []string, env *environ) int {
+	fs := flag.NewFlagSet("solve", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross solve [flags] [file]\n\nReads a puzzle from file (or stdin) and prints its grid, '#' filled, '.' gap and '?' unknown.\n\n")
+		fs.PrintDefaults()
+	}
+	jsonOut := fs.Bool("json", false, "print a JSON report instead of the grid")
+	timeout := fs.Duration("timeout", 0, "give up after this long (0 waits forever)")
+	format := fs.String("format", "", "input format, detected from the content when empty")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return exitInvalid
+	}
+	report := solveReport{}
+	p, err := loadPuzzle(fs.Arg(0), *format, env)
+	if err != nil {
+		report.Status, report.Error = "invalid", err.Error()
+		return printSolveReport(env, report, *jsonOut, exitInvalid)
+	}
+	report.Title, report.Width, report.Height = p.Title, p.Width(), p.Height()
+	grid, err := solveWithTimeout(p, *timeout)
+	code := exitCode(err)
+	report.Status = statusNames[code]
+	if err != nil {
+		report.Error = err.Error()
+	}
+	if grid != nil {
+		report.Grid = gridLines(grid)
+		report.Unknown = countUnknown(grid)
+	}
+	return printSolveReport(env, report, *jsonOut, code)
+}
+
+// printSolveReport writes the result of the solve command, returning its exit code.
+func printSolveReport(env *environ, report solveReport, jsonOut bool, code int) int {
+	if jsonOut {
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		return code
+	}
+	for _, line := range report.Grid {
+		fmt.Fprintln(env.stdout, line)
+	}
+	if report.Error != "" {
+		fmt.Fprintf(env.stderr, "picross: %s: %s\n", report.Status, report.Error)
+	}
+	return code
+}
+
+// statusNames names the outcome of each exit code.
+var statusNames = map[int]string{
+	exitSolved:        "solved",
+	exitContradiction: "contradiction",
+	exitStalled:       "stalled",
+	exitInvalid:       "invalid",
+	exitTimeout:       "timeout",
+}
+
+var errTimeout = errors.New("timed out")
+
+// exitCode maps the outcome of a solve to an exit code.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitSolved
+	case errors.Is(err, errTimeout):
+		return exitTimeout
+	case errors.Is(err, picross.ErrPicrDubious):
+		return exitStalled
+	case errors.Is(err, picross.ErrPicrNoSolution), errors.Is(err, picross.ErrPicrNonsenseHint):
+		return exitContradiction
+	}
+	return exitInvalid
+}
+
+// solveWithTimeout solves a puzzle by line logic, returning the grid it reached.
+// On timeout the solver is abandoned and no grid is returned.
+func solveWithTimeout(p *picross.PicrPuzzle, timeout time.Duration) ([][]picross.CellState, error) {
+	s, err := p.NewSolver(nil)
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Solve() }()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = time.After(timeout)
+	}
+	select {
+	case err := <-done:
+		return s.State(), err
+	case <-expired:
+		return nil, fmt.Errorf("%w after %v", errTimeout, timeout)
+	}
+}
+
+// loadPuzzle reads a puzzle from a file, or from stdin when path is empty or "-".
+func loadPuzzle(path string, format string, env *environ) (*picross.PicrPuzzle, error) {
+	if path == "" || path == "-" {
+		p, _, err := picross.LoadFormat(env.stdin, format)
+		return p, err
+	}
+	if format == "" {
+		return picross.LoadFile(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, _, err := picross.LoadFormat(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return p, nil
+}
+
+// gridLines renders a grid with '#' for filled cells, '.' for gaps and '?' for unknown cells.
+func gridLines(grid [][]picross.CellState) []string {
+	ans := make([]string, len(grid))
+	for i, row := range grid {
+		line := make([]byte, len(row))
+		for j, v := range row {
+			switch v {
+			case picross.Fill:
+				line[j] = '#'
+			case picross.Gap:
+				line[j] = '.'
+			default:
+				line[j] = '?'
+			}
+		}
+		ans[i] = string(line)
+	}
+	return ans
+}
+
+// countUnknown returns the amount of cells of a grid still in the Any state.
+func countUnknown(grid [][]picross.CellState) int {
+	ans := 0
+	for _, row := range grid {
+		for _, v := range row {
+			if v == picross.Any {
+				ans += 1
+			}
+		}
+	}
+	return ans
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const horseTatham = "5x5:1/5/1.2/3/2/3/1.1/4/3/1.1\n"
+
+func TestSolve(t *testing.T) {
+	code, stdout, _ := runCmd(horseTatham, `solve`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v`, code)
+	}
+	if stdout != "###..\n.#..#\n.####\n.###.\n.#.#.\n" {
+		t.Errorf(`unexpected output: %q`, stdout)
+	}
+	path := filepath.Join(t.TempDir(), `horse.txt`)
+	os.WriteFile(path, []byte("rows: 3 | 1 1 | 4 | 3 | 1 1\ncols: 1 | 5 | 1 2 | 3 | 2\n"), 0644)
+	code, stdout, _ = runCmd(``, `solve`, `--json`, `--timeout`, `1m`, path)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v`, code)
+	}
+	var report solveReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if report.Status != `solved` || report.Width != 5 || len(report.Grid) != 5 || report.Unknown != 0 {
+		t.Errorf(`unexpected report: %+v`, report)
+	}
+}
+
+func TestSolveFail(t *testing.T) {
+	checks := []struct {
+		stdin string
+		args  []string
+		code  int
+	}{
+		{"2x2:1/1/1/1\n", []string{`solve`}, exitStalled},
+		{"2x2:2/2/1/2\n", []string{`solve`}, exitContradiction},
+		{"garbage\n", []string{`solve`}, exitInvalid},
+		{horseTatham, []string{`solve`, `--format`, `non`}, exitInvalid},
+		{horseTatham, []string{`solve`, `--bogus`}, exitInvalid},
+		{``, []string{`solve`, `/nonexistent/puzzle.non`}, exitInvalid},
+	}
+	for _, c := range checks {
+		if code, _, _ := runCmd(c.stdin, c.args...); code != c.code {
+			t.Errorf(`%q %v: expected exit code %v, got %v`, c.stdin, c.args, c.code, code)
+		}
+	}
+	code, stdout, _ := runCmd("2x2:1/1/1/1\n", `solve`)
+	if code != exitStalled || stdout != "??\n??\n" {
+		t.Errorf(`unexpected partial output: %v %q`, code, stdout)
+	}
+}
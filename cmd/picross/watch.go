@@ -0,0 +1,189 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func init() {
+	commands["watch"] = command{summary: "show the solver at work in the terminal", run: runWatch}
+}
+
+// 256-color palettes of the watch command, by the round in which cells were found.
+var (
+	watchFillColors = []int{196, 208, 220, 40, 37, 27, 93, 163}
+	watchGapColors  = []int{224, 223, 230, 194, 195, 189, 183, 218}
+)
+
+const (
+	watchAnyColor  = 238
+	watchMonoFill  = 16
+	watchMonoGap   = 255
+	watchHalfBlock = "▀"
+)
+
+// watcher draws a grid in the terminal as it gets determined,
+// two rows of cells per line of text using half blocks.
+type watcher struct {
+	title string
+	grid  [][]picross.CellState
+	// found holds the round in which each cell was determined.
+	found [][]uint
+	round uint
+	// roundEnded tells that no cell was found since the end of the last round.
+	roundEnded bool
+	known      int
+	mono       bool
+}
+
+func runWatch(args []string, env *environ) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: picross watch [flags] [file]\n\nReads a puzzle from file (or stdin) and shows the cells found by the solver as they arrive,\ncoloured by the round in which they were found.\n\n")
+		fs.PrintDefaults()
+	}
+	delay := fs.Duration("delay", 20*time.Millisecond, "pause after each cell")
+	roundDelay := fs.Duration("round-delay", 500*time.Millisecond, "pause after each round")
+	mono := fs.Bool("mono", false, "do not colour cells by round")
+	format := fs.String("format", "", "input format, detected from the content when empty")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return exitInvalid
+	}
+	p, err := loadPuzzle(fs.Arg(0), *format, env)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	// The notification channel is unbuffered, so that the solver waits for every cell to be shown
+	// and the end of a round is signalled only after all of its cells.
+	notifCh := make(chan picross.PicrSolverNotification)
+	s, err := p.NewSolver(notifCh)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %v\n", err)
+		return exitInvalid
+	}
+	roundCh := make(chan struct{})
+	s.OnRound(func() { roundCh <- struct{}{} })
+	done := make(chan error, 1)
+	go func() { done <- s.Solve() }()
+	wt := newWatcher(p, *mono)
+	io.WriteString(env.stdout, wt.frame())
+watchLoop:
+	for {
+		select {
+		case n := <-notifCh:
+			io.WriteString(env.stdout, wt.apply(n))
+			time.Sleep(*delay)
+		case <-roundCh:
+			wt.round, wt.roundEnded = wt.round+1, true
+			io.WriteString(env.stdout, wt.header())
+			time.Sleep(*roundDelay)
+		case err = <-done:
+			break watchLoop
+		}
+	}
+	if wt.roundEnded {
+		// No round followed the last one that ended.
+		wt.round -= 1
+	}
+	code := exitCode(err)
+	fmt.Fprintf(env.stdout, "%s%s\x1b[?25h", wt.header(), wt.moveTo((len(wt.grid)+1)/2+1, 0))
+	if err != nil {
+		fmt.Fprintf(env.stderr, "picross: %s: %s\n", statusNames[code], err)
+	}
+	return code
+}
+
+// newWatcher creates a watcher of a puzzle with every cell unknown.
+func newWatcher(p *picross.PicrPuzzle, mono bool) *watcher {
+	wt := &watcher{title: p.Title, round: 1, mono: mono,
+		grid: make([][]picross.CellState, p.Height()), found: make([][]uint, p.Height())}
+	for i := range wt.grid {
+		wt.grid[i] = make([]picross.CellState, p.Width())
+		wt.found[i] = make([]uint, p.Width())
+	}
+	return wt
+}
+
+// frame clears the screen and draws the whole grid.
+func (wt *watcher) frame() string {
+	var b strings.Builder
+	b.WriteString("\x1b[?25l\x1b[H\x1b[2J")
+	b.WriteString(wt.header())
+	for i := 0; i < len(wt.grid); i += 2 {
+		b.WriteString(wt.moveTo(i/2+1, 0))
+		for j := range wt.grid[i] {
+			b.WriteString(wt.halfBlock(i, j))
+		}
+	}
+	return b.String()
+}
+
+// header draws the line above the grid.
+func (wt *watcher) header() string {
+	total := len(wt.grid) * len(wt.grid[0])
+	line := fmt.Sprintf("round %d, %d of %d cells", wt.round, wt.known, total)
+	if wt.title != "" {
+		line = wt.title + " - " + line
+	}
+	return wt.moveTo(0, 0) + line + "\x1b[K"
+}
+
+// moveTo positions the terminal cursor at a 0-based line and column.
+func (wt *watcher) moveTo(line, col int) string {
+	return fmt.Sprintf("\x1b[%d;%dH", line+1, col+1)
+}
+
+// apply records a solver notification, returning what redraws the affected cell.
+func (wt *watcher) apply(n picross.PicrSolverNotification) string {
+	i, j := int(n.Row())-1, int(n.Col())-1
+	if i < 0 || i >= len(wt.grid) || j < 0 || j >= len(wt.grid[i]) {
+		return ""
+	}
+	if wt.grid[i][j] == picross.Any {
+		wt.known += 1
+	}
+	wt.grid[i][j] = picross.Gap
+	if n.Mark() {
+		wt.grid[i][j] = picross.Fill
+	}
+	wt.found[i][j] = wt.round
+	wt.roundEnded = false
+	i -= i % 2
+	return wt.moveTo(i/2+1, j) + wt.halfBlock(i, j) + wt.header()
+}
+
+// halfBlock draws the cells of a column at an even row and at the row below it.
+func (wt *watcher) halfBlock(i, j int) string {
+	top := wt.color(i, j)
+	if i+1 >= len(wt.grid) {
+		return fmt.Sprintf("\x1b[38;5;%dm%s\x1b[0m", top, watchHalfBlock)
+	}
+	return fmt.Sprintf("\x1b[38;5;%d;48;5;%dm%s\x1b[0m", top, wt.color(i+1, j), watchHalfBlock)
+}
+
+// color returns the 256-color index of a cell.
+func (wt *watcher) color(i, j int) int {
+	k := (int(wt.found[i][j]) - 1) % len(watchFillColors)
+	switch {
+	case wt.grid[i][j] == picross.Fill && wt.mono:
+		return watchMonoFill
+	case wt.grid[i][j] == picross.Fill:
+		return watchFillColors[k]
+	case wt.grid[i][j] == picross.Gap && wt.mono:
+		return watchMonoGap
+	case wt.grid[i][j] == picross.Gap:
+		return watchGapColors[k]
+	}
+	return watchAnyColor
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	picross "github.com/coolparadox/picross-go"
+)
+
+func TestWatch(t *testing.T) {
+	code, stdout, _ := runCmd(horseTatham, `watch`, `-delay`, `0`, `-round-delay`, `0`)
+	if code != exitSolved {
+		t.Fatalf(`unexpected exit code: %v`, code)
+	}
+	if !strings.HasSuffix(stdout, "round 3, 25 of 25 cells\x1b[K\x1b[5;1H\x1b[?25h") {
+		t.Errorf(`unexpected end of output: %q`, stdout[len(stdout)-60:])
+	}
+	if n := strings.Count(stdout, watchHalfBlock); n != 15+25 {
+		t.Errorf(`unexpected amount of half blocks: %v`, n)
+	}
+	code, _, stderr := runCmd("2x2:1/1/1/1\n", `watch`, `-delay`, `0`, `-round-delay`, `0`)
+	if code != exitStalled || !strings.Contains(stderr, `stalled`) {
+		t.Errorf(`unexpected result: %v %q`, code, stderr)
+	}
+	for _, args := range [][]string{{`watch`, `a`, `b`}, {`watch`, `/nonexistent`}, {`watch`, `-bogus`}} {
+		if code, _, _ := runCmd(horseTatham, args...); code != exitInvalid {
+			t.Errorf(`%v: unexpected exit code: %v`, args, code)
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	p, _ := picross.NewPicrPuzzle([][]uint{{1}, {0}, {1}}, [][]uint{{2}})
+	p.Title = `tiny`
+	wt := newWatcher(p, false)
+	if frame := wt.frame(); !strings.Contains(frame, "tiny - round 1, 0 of 3 cells") || strings.Count(frame, watchHalfBlock) != 2 {
+		t.Errorf(`unexpected frame: %q`, frame)
+	}
+	if wt.color(0, 0) != watchAnyColor {
+		t.Errorf(`unexpected color of unknown cell: %v`, wt.color(0, 0))
+	}
+	wt.grid[0][0], wt.found[0][0] = picross.Fill, 1
+	wt.grid[2][0], wt.found[2][0] = picross.Gap, 10
+	if wt.color(0, 0) != watchFillColors[0] || wt.color(2, 0) != watchGapColors[1] {
+		t.Errorf(`unexpected colors: %v %v`, wt.color(0, 0), wt.color(2, 0))
+	}
+	if block := wt.halfBlock(2, 0); block != "\x1b[38;5;223m"+watchHalfBlock+"\x1b[0m" {
+		t.Errorf(`unexpected half block: %q`, block)
+	}
+	wt.mono = true
+	if wt.color(0, 0) != watchMonoFill || wt.color(2, 0) != watchMonoGap {
+		t.Errorf(`unexpected mono colors: %v %v`, wt.color(0, 0), wt.color(2, 0))
+	}
+}
@@ -0,0 +1,212 @@
+package picross
+
+import (
+	"errors"
+	"sort"
+)
+
+// PicrLine is a line of a puzzle with its clue; Axis is "row" or "col" and Index is 1-based.
+type PicrLine struct {
+	Axis  string `json:"axis"`
+	Index uint   `json:"index"`
+	Clue  []uint `json:"clue"`
+}
+
+// PicrClueEdit is a change to the clue of a single line that makes a puzzle consistent.
+type PicrClueEdit struct {
+	PicrLine
+	// To is the new clue; Unique tells whether the edited puzzle has a unique solution.
+	To     []uint `json:"to"`
+	Unique bool   `json:"unique"`
+}
+
+// PicrExplanation tells why the clues of a puzzle contradict each other.
+type PicrExplanation struct {
+	// Core is a set of lines whose clues alone contradict each other,
+	// with no line to spare: relaxing any of them leaves the others consistent.
+	Core []PicrLine `json:"core"`
+	// Edits are single clue changes that make the puzzle consistent, those giving a unique solution first.
+	Edits []PicrClueEdit `json:"edits"`
+}
+
+// ErrPicrConsistent reports that the clues of a puzzle have a solution, so there is no contradiction to explain.
+var ErrPicrConsistent = errors.New("PicrExplain: clues are consistent")
+
+// picrExplainEdits is the largest amount of new clues proposed for a line.
+const picrExplainEdits = 5
+
+// picrRelaxation marks the lines of a puzzle whose clues are left out.
+type picrRelaxation struct {
+	rows []bool
+	cols []bool
+}
+
+// newPicrRelaxation marks every line of a puzzle as relaxed or not.
+func newPicrRelaxation(p *PicrPuzzle, relaxed bool) picrRelaxation {
+	r := picrRelaxation{rows: make([]bool, p.Height()), cols: make([]bool, p.Width())}
+	for i := range r.rows {
+		r.rows[i] = relaxed
+	}
+	for j := range r.cols {
+		r.cols[j] = relaxed
+	}
+	return r
+}
+
+// relaxed tells whether a line is relaxed, `line` counting rows first and then columns.
+func (r picrRelaxation) relaxed(line int) *bool {
+	if line < len(r.rows) {
+		return &r.rows[line]
+	}
+	return &r.cols[line-len(r.rows)]
+}
+
+// picrRelaxedSolutions enumerates up to `limit` solutions of a puzzle with some lines relaxed,
+// by line logic over the remaining lines and guessing wherever it stalls.
+// Cells whose row and column are both relaxed are taken as gaps.
+func picrRelaxedSolutions(p *PicrPuzzle, relax picrRelaxation, limit int) ([][][]CellState, error) {
+	seed := make([][]CellState, p.Height())
+	for i := range seed {
+		seed[i] = make([]CellState, p.Width())
+		for j := range seed[i] {
+			if relax.rows[i] && relax.cols[j] {
+				seed[i][j] = Gap
+			}
+		}
+	}
+	var ans [][][]CellState
+	var explore func(seed [][]CellState) error
+	explore = func(seed [][]CellState) error {
+		s, err := p.NewSolver(nil)
+		if err != nil {
+			return err
+		}
+		for i, w := range s.row.workers {
+			w.free = relax.rows[i]
+		}
+		for j, w := range s.col.workers {
+			w.free = relax.cols[j]
+		}
+		if err := s.row.work(seed); err != nil {
+			return nil
+		}
+		err = s.solve()
+		if isPicrContradiction(err) {
+			return nil
+		}
+		grid := s.getState()
+		if err == nil {
+			ans = append(ans, grid)
+			return nil
+		}
+		row, col := picrFirstAny(grid)
+		for _, v := range []CellState{Fill, Gap} {
+			if len(ans) >= limit {
+				return nil
+			}
+			next := picrCopyMap(grid)
+			next[row][col] = v
+			if err := explore(next); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := explore(seed); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+// picrLineOf returns a line of a puzzle, `line` counting rows first and then columns.
+func picrLineOf(p *PicrPuzzle, line int) PicrLine {
+	if line < len(p.RowClues) {
+		return PicrLine{Axis: "row", Index: uint(line) + 1, Clue: p.RowClues[line]}
+	}
+	line -= len(p.RowClues)
+	return PicrLine{Axis: "col", Index: uint(line) + 1, Clue: p.ColClues[line]}
+}
+
+// Explain finds a small set of lines whose clues already contradict each other,
+// by relaxing lines one at a time and keeping relaxed those the contradiction does without.
+// It also proposes edits of the clue of a single line of that set that make the puzzle consistent.
+// ErrPicrConsistent is returned when the puzzle has a solution.
+func (p *PicrPuzzle) Explain() (*PicrExplanation, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	relax := newPicrRelaxation(p, false)
+	sols, err := picrRelaxedSolutions(p, relax, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(sols) > 0 {
+		return nil, ErrPicrConsistent
+	}
+	e := &PicrExplanation{Core: make([]PicrLine, 0), Edits: make([]PicrClueEdit, 0)}
+	var core []int
+	for line := 0; line < len(p.RowClues)+len(p.ColClues); line++ {
+		*relax.relaxed(line) = true
+		sols, err := picrRelaxedSolutions(p, relax, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(sols) > 0 {
+			// The contradiction needs this line.
+			*relax.relaxed(line) = false
+			core = append(core, line)
+		}
+	}
+	for _, line := range core {
+		e.Core = append(e.Core, picrLineOf(p, line))
+		edits, err := p.clueEdits(line)
+		if err != nil {
+			return nil, err
+		}
+		e.Edits = append(e.Edits, edits...)
+	}
+	sort.SliceStable(e.Edits, func(a, b int) bool { return e.Edits[a].Unique && !e.Edits[b].Unique })
+	return e, nil
+}
+
+// clueEdits proposes new clues for a line that make the puzzle consistent,
+// taken from the solutions of the puzzle with that line relaxed.
+func (p *PicrPuzzle) clueEdits(line int) ([]PicrClueEdit, error) {
+	relax := newPicrRelaxation(p, false)
+	*relax.relaxed(line) = true
+	sols, err := picrRelaxedSolutions(p, relax, 100)
+	if err != nil {
+		return nil, err
+	}
+	orig := picrLineOf(p, line)
+	var ans []PicrClueEdit
+	seen := map[string]bool{}
+	for _, sol := range sols {
+		var clue []uint
+		if orig.Axis == "row" {
+			clue = picrLineClue(sol[orig.Index-1])
+		} else {
+			clue = picrLineClue(picrTranspose(sol)[orig.Index-1])
+		}
+		key := picrFormatClue(clue)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		q := &PicrPuzzle{RowClues: append([][]uint{}, p.RowClues...), ColClues: append([][]uint{}, p.ColClues...)}
+		if orig.Axis == "row" {
+			q.RowClues[orig.Index-1] = clue
+		} else {
+			q.ColClues[orig.Index-1] = clue
+		}
+		r, err := q.Search(2)
+		if err != nil {
+			return nil, err
+		}
+		ans = append(ans, PicrClueEdit{PicrLine: orig, To: clue, Unique: len(r.Solutions) == 1})
+		if len(ans) >= picrExplainEdits {
+			break
+		}
+	}
+	return ans, nil
+}
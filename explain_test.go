@@ -0,0 +1,75 @@
+package picross
+
+import (
+	"testing"
+)
+
+// checkPicrCore verifies that the lines of a core contradict each other and that none of them is spare.
+func checkPicrCore(t *testing.T, p *PicrPuzzle, core []PicrLine) {
+	t.Helper()
+	relax := newPicrRelaxation(p, true)
+	index := func(l PicrLine) int {
+		if l.Axis == "row" {
+			return int(l.Index) - 1
+		}
+		return len(p.RowClues) + int(l.Index) - 1
+	}
+	for _, l := range core {
+		*relax.relaxed(index(l)) = false
+	}
+	if sols, _ := picrRelaxedSolutions(p, relax, 1); len(sols) != 0 {
+		t.Errorf(`core is consistent: %v`, core)
+	}
+	for _, l := range core {
+		*relax.relaxed(index(l)) = true
+		if sols, _ := picrRelaxedSolutions(p, relax, 1); len(sols) == 0 {
+			t.Errorf(`%s %d is spare in the core`, l.Axis, l.Index)
+		}
+		*relax.relaxed(index(l)) = false
+	}
+}
+
+func TestPicrExplain(t *testing.T) {
+	// A typo in the second row of the horse.
+	p := horsePuzzle()
+	p.RowClues[1] = []uint{2, 1}
+	e, err := p.Explain()
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	checkPicrCore(t, p, e.Core)
+	if len(e.Core) != 6 || e.Core[1].Axis != `row` || e.Core[1].Index != 2 {
+		t.Errorf(`unexpected core: %v`, e.Core)
+	}
+	fixed := false
+	for _, edit := range e.Edits {
+		q := &PicrPuzzle{RowClues: append([][]uint{}, p.RowClues...), ColClues: append([][]uint{}, p.ColClues...)}
+		if edit.Axis == `row` {
+			q.RowClues[edit.Index-1] = edit.To
+		} else {
+			q.ColClues[edit.Index-1] = edit.To
+		}
+		if r, _ := q.Search(2); len(r.Solutions) == 0 || edit.Unique != (len(r.Solutions) == 1) {
+			t.Errorf(`edit does not hold: %+v`, edit)
+		}
+		fixed = fixed || (edit.Axis == `row` && edit.Index == 2 && areSlicesEqual(edit.To, []uint{1, 1}))
+	}
+	if !fixed {
+		t.Errorf(`typo not found among the edits: %+v`, e.Edits)
+	}
+}
+
+func TestPicrExplainNoSingleEdit(t *testing.T) {
+	p := &PicrPuzzle{RowClues: [][]uint{{2}, {0}}, ColClues: [][]uint{{2}, {0}}}
+	e, err := p.Explain()
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	checkPicrCore(t, p, e.Core)
+	if len(e.Core) != 2 || len(e.Edits) != 0 {
+		t.Errorf(`unexpected explanation: %+v`, e)
+	}
+	if _, err := horsePuzzle().Explain(); err != ErrPicrConsistent {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+}
@@ -0,0 +1,102 @@
+package picross
+
+import (
+	"unicode"
+)
+
+// picrGlyphs is a tiny bitmap font used where no vector text is available,
+// such as when rendering raster images.
+// All glyphs share the same height, most of them the same width; '#' marks an inked pixel.
+var picrGlyphs = map[rune][]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'B': {"##.", "#.#", "##.", "#.#", "##."},
+	'C': {".##", "#..", "#..", "#..", ".##"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'G': {".##", "#..", "#.#", "#.#", ".##"},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I': {"###", ".#.", ".#.", ".#.", "###"},
+	'J': {"..#", "..#", "..#", "#.#", ".#."},
+	'K': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'M': {"#...#", "##.##", "#.#.#", "#...#", "#...#"},
+	'N': {"#..#", "##.#", "#.##", "#..#", "#..#"},
+	'O': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P': {"##.", "#.#", "##.", "#..", "#.."},
+	'Q': {".#.", "#.#", "#.#", "##.", ".##"},
+	'R': {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S': {".##", "#..", ".#.", "..#", "##."},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W': {"#...#", "#...#", "#.#.#", "##.##", "#...#"},
+	'X': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z': {"###", "..#", ".#.", "#..", "###"},
+	'-': {"...", "...", "###", "...", "..."},
+	'.': {".", ".", ".", ".", "#"},
+	'!': {"#", "#", "#", ".", "#"},
+	' ': {"...", "...", "...", "...", "..."},
+	'?': {"###", "..#", ".##", "...", ".#."},
+}
+
+// picrGlyphHeight is the height in pixels of every glyph of picrGlyphs.
+const picrGlyphHeight = 5
+
+// picrGlyph returns the bitmap of a rune, lower case letters looking as upper case ones,
+// falling back to '?' for unknown runes.
+func picrGlyph(r rune) []string {
+	if g, ok := picrGlyphs[unicode.ToUpper(r)]; ok {
+		return g
+	}
+	return picrGlyphs['?']
+}
+
+// picrTextWidth returns the width in pixels of a string rendered with picrGlyphs,
+// including a single pixel of spacing between glyphs.
+func picrTextWidth(s string) int {
+	ans := 0
+	for _, r := range s {
+		if ans > 0 {
+			ans += 1
+		}
+		ans += len(picrGlyph(r)[0])
+	}
+	return ans
+}
+
+// picrTextGrid renders a string with picrGlyphs into a grid,
+// inked pixels becoming filled cells, with a single gap column between glyphs.
+func picrTextGrid(s string) [][]CellState {
+	ans := make([][]CellState, picrGlyphHeight)
+	for i := range ans {
+		ans[i] = make([]CellState, 0, picrTextWidth(s))
+	}
+	for k, r := range s {
+		g := picrGlyph(r)
+		for i, line := range g {
+			if k > 0 {
+				ans[i] = append(ans[i], Gap)
+			}
+			for _, c := range line {
+				v := Gap
+				if c == '#' {
+					v = Fill
+				}
+				ans[i] = append(ans[i], v)
+			}
+		}
+	}
+	return ans
+}
@@ -0,0 +1,316 @@
+package picross
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// PicrFormat describes a puzzle file format.
+// Decode and Encode may be nil for formats that can only be written or only be read.
+type PicrFormat struct {
+	Name string
+	// Extensions lists the file name suffixes of the format, including the leading dot.
+	Extensions []string
+	// Sniff tells whether the beginning of a content looks like this format.
+	// A nil Sniff leaves the format to be chosen by name or by extension only.
+	Sniff  func(head []byte) bool
+	Decode func(r io.Reader) (*PicrPuzzle, error)
+	Encode func(w io.Writer, p *PicrPuzzle) error
+}
+
+// picrSniffLen is the amount of bytes that the sniffers get to look at.
+const picrSniffLen = 1 << 16
+
+var (
+	picrFormatsMu sync.Mutex
+	picrFormats   []PicrFormat
+)
+
+// RegisterPicrFormat makes a format available to Load and Save.
+// Formats are sniffed in registration order;
+// registering a format with the name of an existing one replaces it.
+func RegisterPicrFormat(f PicrFormat) {
+	picrFormatsMu.Lock()
+	defer picrFormatsMu.Unlock()
+	for i, g := range picrFormats {
+		if g.Name == f.Name {
+			picrFormats[i] = f
+			return
+		}
+	}
+	picrFormats = append(picrFormats, f)
+}
+
+// PicrFormats returns the registered formats, in registration order.
+func PicrFormats() []PicrFormat {
+	picrFormatsMu.Lock()
+	defer picrFormatsMu.Unlock()
+	ans := make([]PicrFormat, len(picrFormats))
+	copy(ans, picrFormats)
+	return ans
+}
+
+// PicrFormatByName returns the registered format of a given name.
+func PicrFormatByName(name string) (PicrFormat, bool) {
+	for _, f := range PicrFormats() {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return PicrFormat{}, false
+}
+
+// PicrFormatByPath returns the registered format whose longest extension matches a file name.
+func PicrFormatByPath(path string) (PicrFormat, bool) {
+	base := strings.ToLower(filepath.Base(path))
+	var ans PicrFormat
+	var best int
+	for _, f := range PicrFormats() {
+		for _, ext := range f.Extensions {
+			if len(ext) > best && strings.HasSuffix(base, ext) {
+				ans, best = f, len(ext)
+			}
+		}
+	}
+	return ans, best > 0
+}
+
+// Load decodes a puzzle, detecting its format from the content.
+func Load(r io.Reader) (*PicrPuzzle, error) {
+	p, _, err := loadPicr(r, "")
+	return p, err
+}
+
+// LoadFile decodes the puzzle stored in a file,
+// detecting its format from the content or else from the file name.
+func LoadFile(path string) (*PicrPuzzle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, _, err := loadPicr(f, path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return p, nil
+}
+
+// LoadFormat decodes a puzzle in the named format, or detects it from the content if `format` is empty.
+// It also returns the name of the decoded format.
+func LoadFormat(r io.Reader, format string) (*PicrPuzzle, string, error) {
+	if format == "" {
+		return loadPicr(r, "")
+	}
+	f, ok := PicrFormatByName(format)
+	if !ok || f.Decode == nil {
+		return nil, "", fmt.Errorf("PicrFormat: cannot read format %q", format)
+	}
+	p, err := f.Decode(r)
+	return p, f.Name, err
+}
+
+// loadPicr sniffs the content of a reader, falling back to the extension of `path` (if any).
+func loadPicr(r io.Reader, path string) (*PicrPuzzle, string, error) {
+	br := bufio.NewReaderSize(r, picrSniffLen)
+	head, err := br.Peek(picrSniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, "", err
+	}
+	for _, f := range PicrFormats() {
+		if f.Sniff == nil || f.Decode == nil || !f.Sniff(head) {
+			continue
+		}
+		p, err := f.Decode(br)
+		return p, f.Name, err
+	}
+	if path != "" {
+		if f, ok := PicrFormatByPath(path); ok && f.Decode != nil {
+			p, err := f.Decode(br)
+			return p, f.Name, err
+		}
+	}
+	return nil, "", errors.New("PicrFormat: unrecognized puzzle format")
+}
+
+// Save encodes a puzzle in the named format.
+func Save(w io.Writer, p *PicrPuzzle, format string) error {
+	f, ok := PicrFormatByName(format)
+	if !ok || f.Encode == nil {
+		return fmt.Errorf("PicrFormat: cannot write format %q", format)
+	}
+	return f.Encode(w, p)
+}
+
+// SaveFile writes a puzzle to a file in the named format,
+// or in the format matching the file name if `format` is empty.
+func SaveFile(path string, p *PicrPuzzle, format string) error {
+	if format == "" {
+		f, ok := PicrFormatByPath(path)
+		if !ok {
+			return fmt.Errorf("PicrFormat: no format for file name %q", path)
+		}
+		format = f.Name
+	}
+	var buf bytes.Buffer
+	if err := Save(&buf, p, format); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+var (
+	picrTathamSniffRe  = regexp.MustCompile(`^\s*\d+(x\d+)?[:#]`)
+	picrNonSniffRe     = regexp.MustCompile(`(?m)^\s*(width|height)\s+\d+\s*$`)
+	picrRosettaSniffRe = regexp.MustCompile(`^\s*[A-Z]+( +[A-Z]+)*[ \t]*\r?\n\s*[A-Z]+( +[A-Z]+)*\s*$`)
+)
+
+func init() {
+	RegisterPicrFormat(PicrFormat{
+		Name:       "png",
+		Extensions: []string{".png"},
+		Sniff:      func(head []byte) bool { return bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")) },
+		Decode:     func(r io.Reader) (*PicrPuzzle, error) { return DecodePicrImage(r, PicrImageOptions{}) },
+		Encode:     encodePicrPNG,
+	})
+	RegisterPicrFormat(PicrFormat{
+		Name:       "pnm",
+		Extensions: []string{".pbm", ".pgm", ".pnm"},
+		Sniff: func(head []byte) bool {
+			return len(head) > 2 && head[0] == 'P' && strings.IndexByte("1245", head[1]) >= 0 && strings.IndexByte(" \t\r\n#", head[2]) >= 0
+		},
+		Decode: func(r io.Reader) (*PicrPuzzle, error) { return DecodePicrImage(r, PicrImageOptions{}) },
+		Encode: encodePbm,
+	})
+	RegisterPicrFormat(PicrFormat{
+		Name:       "pack",
+		Extensions: []string{".pack.json"},
+		Sniff: func(head []byte) bool {
+			return bytes.HasPrefix(bytes.TrimSpace(head), []byte("{")) && bytes.Contains(head, []byte(`"`+picrPackFormat+`"`))
+		},
+		Decode: decodePicrPackPuzzle,
+		Encode: func(w io.Writer, p *PicrPuzzle) error {
+			e, err := NewPicrPackEntry("1", p)
+			if err != nil {
+				return err
+			}
+			pk := &PicrPack{Title: p.Title}
+			if err := pk.Append(e); err != nil {
+				return err
+			}
+			return pk.Write(w)
+		},
+	})
+	RegisterPicrFormat(PicrFormat{
+		Name:       "json",
+		Extensions: []string{".json"},
+		Sniff:      func(head []byte) bool { return bytes.HasPrefix(bytes.TrimSpace(head), []byte("{")) },
+		Decode:     decodePicrJSON,
+		Encode:     encodePicrJSON,
+	})
+	RegisterPicrFormat(PicrFormat{
+		Name:       "webpbn",
+		Extensions: []string{".xml", ".pbn"},
+		Sniff: func(head []byte) bool {
+			return bytes.HasPrefix(bytes.TrimSpace(head), []byte("<")) && bytes.Contains(head, []byte("<puzzle"))
+		},
+		Decode: ParseWebpbn,
+		Encode: FormatWebpbn,
+	})
+	RegisterPicrFormat(PicrFormat{
+		Name:       "nonogramsorg",
+		Extensions: []string{".html", ".htm"},
+		Sniff: func(head []byte) bool {
+			return bytes.HasPrefix(bytes.TrimSpace(head), []byte("<")) && bytes.Contains(bytes.ToLower(head), []byte("nonograms.org"))
+		},
+		Decode: ParseNonogramsOrgPage,
+	})
+	RegisterPicrFormat(PicrFormat{
+		Name:       "tatham",
+		Extensions: []string{".tatham"},
+		Sniff:      picrTathamSniffRe.Match,
+		Decode: func(r io.Reader) (*PicrPuzzle, error) {
+			id, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			return ParseTathamGameID(string(id))
+		},
+		Encode: func(w io.Writer, p *PicrPuzzle) error {
+			id, err := FormatTathamGameID(p)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(w, id)
+			return err
+		},
+	})
+	RegisterPicrFormat(PicrFormat{
+		Name:       "non",
+		Extensions: []string{".non"},
+		Sniff:      picrNonSniffRe.Match,
+		Decode:     ParseNon,
+		Encode:     FormatNon,
+	})
+	RegisterPicrFormat(PicrFormat{
+		Name:       "rosetta",
+		Extensions: []string{".rosetta"},
+		Sniff:      picrRosettaSniffRe.Match,
+		Decode:     ParseRosetta,
+		Encode:     FormatRosetta,
+	})
+	RegisterPicrFormat(PicrFormat{
+		Name:       "svg",
+		Extensions: []string{".svg"},
+		Encode:     func(w io.Writer, p *PicrPuzzle) error { return RenderPicrSVG(w, p, PicrRenderOptions{}) },
+	})
+	// The PNG clue sheet cannot be read back; .png files hold the goal picture.
+	RegisterPicrFormat(PicrFormat{
+		Name:   "png-sheet",
+		Encode: func(w io.Writer, p *PicrPuzzle) error { return RenderPicrPNG(w, p, PicrRenderOptions{}) },
+	})
+}
+
+// decodePicrPackPuzzle reads the single puzzle of a pack.
+func decodePicrPackPuzzle(r io.Reader) (*PicrPuzzle, error) {
+	pk, err := ReadPicrPack(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(pk.Entries) != 1 {
+		return nil, fmt.Errorf("PicrPack: expected a single puzzle, found %d", len(pk.Entries))
+	}
+	return pk.Entries[0].Puzzle()
+}
+
+// decodePicrJSON reads a single puzzle encoded as a pack entry, where the sizes may be omitted.
+func decodePicrJSON(r io.Reader) (*PicrPuzzle, error) {
+	var e PicrPackEntry
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, fmt.Errorf("PicrJSON: %v", err)
+	}
+	if e.Width == 0 && e.Height == 0 {
+		e.Width, e.Height = uint(len(e.ColClues)), uint(len(e.RowClues))
+	}
+	return e.Puzzle()
+}
+
+// encodePicrJSON writes a single puzzle encoded as a pack entry.
+func encodePicrJSON(w io.Writer, p *PicrPuzzle) error {
+	e, err := NewPicrPackEntry("", p)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e)
+}
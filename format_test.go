@@ -0,0 +1,79 @@
+package picross
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	p := horsePuzzle()
+	p.Goal = str2Map(`###..
+                      .#..#
+                      .####
+                      .###.
+                      .#.#.`)
+	for _, format := range []string{`png`, `pnm`, `pack`, `json`, `webpbn`, `tatham`, `non`, `rosetta`, `text`} {
+		var buf bytes.Buffer
+		if err := Save(&buf, p, format); err != nil {
+			t.Errorf(`%v: unexpected error: %v`, format, err)
+			continue
+		}
+		q, name, err := loadPicr(&buf, ``)
+		if err != nil {
+			t.Errorf(`%v: unexpected error: %v`, format, err)
+			continue
+		}
+		if name != format {
+			t.Errorf(`%v: detected as %v`, format, name)
+		}
+		if !areSlices2Equal(q.RowClues, p.RowClues) || !areSlices2Equal(q.ColClues, p.ColClues) {
+			t.Errorf(`%v: clues mismatch: %v %v`, format, q.RowClues, q.ColClues)
+		}
+	}
+	var buf bytes.Buffer
+	if err := Save(&buf, p, `svg`); err != nil {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+	if _, err := Load(&buf); err == nil {
+		t.Errorf(`unexpected success loading svg`)
+	}
+	buf.Reset()
+	if err := Save(&buf, p, `png-sheet`); err != nil {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+	if err := Save(&buf, p, `nonogramsorg`); err == nil {
+		t.Errorf(`unexpected success saving a read only format`)
+	}
+}
+
+func TestSaveLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	p := horsePuzzle()
+	path := filepath.Join(dir, `horse.pack.json`)
+	if err := SaveFile(path, p, ``); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if f, _ := PicrFormatByPath(path); f.Name != `pack` {
+		t.Errorf(`unexpected format for %v: %v`, path, f.Name)
+	}
+	if _, err := LoadFile(path); err != nil {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+	if err := SaveFile(filepath.Join(dir, `horse.unknown`), p, ``); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
+
+func TestRegisterPicrFormat(t *testing.T) {
+	RegisterPicrFormat(PicrFormat{
+		Name:   `test-magic`,
+		Sniff:  func(head []byte) bool { return bytes.HasPrefix(head, []byte(`MAGIC`)) },
+		Decode: func(r io.Reader) (*PicrPuzzle, error) { return horsePuzzle(), nil },
+	})
+	p, name, err := loadPicr(bytes.NewBufferString(`MAGIC`), ``)
+	if err != nil || name != `test-magic` || p.Width() != 5 {
+		t.Errorf(`unexpected result: %v %v %v`, p, name, err)
+	}
+}
@@ -0,0 +1,136 @@
+package picross
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PicrGame is a puzzle being played: a grid of marks that the player sets freely,
+// checked against the clues rather than against a goal grid.
+type PicrGame struct {
+	Puzzle *PicrPuzzle
+	// Grid holds the marks of the player, indexed by row and then by column.
+	Grid [][]CellState
+	undo []picrGameMove
+}
+
+// picrGameMove records a mark, so that it can be undone.
+type picrGameMove struct {
+	row, col int
+	old      CellState
+	// group ties the marks that were done together, such as the cells of a drag.
+	group uint
+}
+
+// NewPicrGame starts a game of a puzzle with an empty grid.
+func NewPicrGame(p *PicrPuzzle) (*PicrGame, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	g := &PicrGame{Puzzle: p, Grid: make([][]CellState, p.Height())}
+	for i := range g.Grid {
+		g.Grid[i] = make([]CellState, p.Width())
+	}
+	return g, nil
+}
+
+// Set marks a cell, returning whether it changed.
+// Marks set with the same nonzero `group` are undone together.
+func (g *PicrGame) Set(row, col int, v CellState, group uint) bool {
+	if row < 0 || row >= len(g.Grid) || col < 0 || col >= len(g.Grid[row]) || g.Grid[row][col] == v {
+		return false
+	}
+	g.undo = append(g.undo, picrGameMove{row: row, col: col, old: g.Grid[row][col], group: group})
+	g.Grid[row][col] = v
+	return true
+}
+
+// Undo reverts the last mark, or the last group of marks, returning whether there was anything to undo.
+func (g *PicrGame) Undo() bool {
+	if len(g.undo) < 1 {
+		return false
+	}
+	group := g.undo[len(g.undo)-1].group
+	for len(g.undo) > 0 {
+		m := g.undo[len(g.undo)-1]
+		if m.group != group {
+			break
+		}
+		g.undo = g.undo[:len(g.undo)-1]
+		g.Grid[m.row][m.col] = m.old
+		if group == 0 {
+			break
+		}
+	}
+	return true
+}
+
+// column returns a column of the grid.
+func (g *PicrGame) column(col int) []CellState {
+	ans := make([]CellState, len(g.Grid))
+	for i, row := range g.Grid {
+		ans[i] = row[col]
+	}
+	return ans
+}
+
+// RowDone tells whether the filled cells of a row match its clue.
+func (g *PicrGame) RowDone(row int) bool {
+	return picrSameClue(picrLineClue(g.Grid[row]), g.Puzzle.RowClues[row])
+}
+
+// ColDone tells whether the filled cells of a column match its clue.
+func (g *PicrGame) ColDone(col int) bool {
+	return picrSameClue(picrLineClue(g.column(col)), g.Puzzle.ColClues[col])
+}
+
+// Solved tells whether the filled cells of every line match the clues.
+// Unmarked cells count as gaps, and a solution other than the goal of the puzzle is accepted.
+func (g *PicrGame) Solved() bool {
+	for i := range g.Grid {
+		if !g.RowDone(i) {
+			return false
+		}
+	}
+	for j := range g.Puzzle.ColClues {
+		if !g.ColDone(j) {
+			return false
+		}
+	}
+	return true
+}
+
+// HintRow applies line logic to a row, as marked by the player.
+// It returns the row with the cells that its clue alone determines,
+// or ErrPicrNoSolution when the marks of the row contradict its clue.
+func (g *PicrGame) HintRow(row int) ([]CellState, error) {
+	if row < 0 || row >= len(g.Grid) {
+		return nil, fmt.Errorf("PicrGame: no row %d", row+1)
+	}
+	return PicrLineHint(g.Puzzle.RowClues[row], g.Grid[row])
+}
+
+// HintCol applies line logic to a column, as marked by the player; see HintRow.
+func (g *PicrGame) HintCol(col int) ([]CellState, error) {
+	if col < 0 || col >= len(g.Puzzle.ColClues) {
+		return nil, fmt.Errorf("PicrGame: no column %d", col+1)
+	}
+	return PicrLineHint(g.Puzzle.ColClues[col], g.column(col))
+}
+
+// PicrLineHint determines what can be told of a single line from its clue and its known cells.
+func PicrLineHint(clue []uint, line []CellState) ([]CellState, error) {
+	if len(line) < 1 {
+		return nil, errors.New("PicrWorker: empty line")
+	}
+	w, err := NewPicrWorker(uint(len(line)), clue, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.work(line); err != nil {
+		return nil, err
+	}
+	ans := make([]CellState, len(line))
+	copy(ans, w.getHint())
+	return ans, nil
+}
@@ -0,0 +1,70 @@
+package picross
+
+import (
+	"testing"
+)
+
+func TestPicrGame(t *testing.T) {
+	g, err := NewPicrGame(horsePuzzle())
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if g.Solved() || g.RowDone(0) || picrCountAny(g.Grid) != 25 {
+		t.Errorf(`unexpected initial game: %v`, g.Grid)
+	}
+	for i, row := range str2Map(`###..
+                                 .#..#
+                                 .####
+                                 .###.
+                                 .#.#.`) {
+		for j, v := range row {
+			if v == Fill {
+				g.Set(i, j, Fill, 0)
+			}
+		}
+	}
+	if !g.Solved() {
+		t.Errorf(`game not solved: %v`, g.Grid)
+	}
+	if g.Set(0, 0, Fill, 0) || g.Set(5, 0, Fill, 0) {
+		t.Errorf(`unexpected change`)
+	}
+	g.Set(0, 3, Fill, 1)
+	g.Set(0, 4, Fill, 1)
+	if g.Solved() || g.RowDone(0) || g.ColDone(3) || !g.ColDone(0) {
+		t.Errorf(`unexpected completion`)
+	}
+	if !g.Undo() || g.Grid[0][3] != Any || g.Grid[0][4] != Any || !g.Solved() {
+		t.Errorf(`unexpected undo: %v`, g.Grid)
+	}
+	g.Set(4, 4, Gap, 0)
+	if !g.Undo() || g.Grid[4][4] != Any || !g.Undo() || g.Grid[4][3] != Any || !g.Undo() {
+		t.Errorf(`unexpected undo: %v`, g.Grid)
+	}
+	if _, err := NewPicrGame(&PicrPuzzle{}); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
+
+func TestPicrGameHint(t *testing.T) {
+	g, _ := NewPicrGame(horsePuzzle())
+	hint, err := g.HintCol(1)
+	if err != nil || picrFormatRow(hint) != `#####` {
+		t.Errorf(`unexpected hint: %v %v`, hint, err)
+	}
+	g.Set(2, 0, Fill, 0)
+	hint, err = g.HintRow(2)
+	if err != nil || picrFormatRow(hint) != `####.` {
+		t.Errorf(`unexpected hint: %v %v`, hint, err)
+	}
+	g.Set(2, 4, Fill, 0)
+	if _, err = g.HintRow(2); err != ErrPicrNoSolution {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+	if _, err = g.HintRow(5); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+	if _, err = PicrLineHint([]uint{1}, []CellState{}); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
@@ -0,0 +1,317 @@
+package picross
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// PicrGenerateOptions controls the puzzles made by a PicrGenerator.
+type PicrGenerateOptions struct {
+	Width  uint
+	Height uint
+	// Density is the fraction of filled cells, from 0 to 1. Zero selects 0.5.
+	Density float64
+	// Clustering, from 0 to 1, smooths the random grid so that filled cells gather in blobs
+	// instead of looking like noise. Zero keeps the noise.
+	Clustering float64
+	// LineSolvable keeps only puzzles that line logic alone solves;
+	// otherwise any puzzle with a unique solution is kept.
+	LineSolvable bool
+	// Seed makes the sequence of puzzles reproducible.
+	Seed int64
+	// MaxAttempts is the amount of grids tried for each puzzle before giving up. Zero selects 1000.
+	MaxAttempts uint
+	// Symmetry, when not PicrIdentity, is a symmetry that leaves every goal unchanged.
+	// Symmetries involving a transposition need a square grid.
+	Symmetry PicrSymmetry
+	// Connected keeps only goals whose filled cells are all joined through their sides.
+	Connected bool
+	// MaxRuns, when not zero, is the largest amount of runs in the clue of any line.
+	MaxRuns uint
+	// MinFill and MaxFill bound the fraction of filled cells of the goals; zero MaxFill selects 1.
+	// When Density is zero and a bound is given, the density of each grid is drawn between them.
+	MinFill float64
+	MaxFill float64
+	// Text, when not empty, is rendered with a bitmap font into the goal, at a random place within the grid,
+	// instead of drawing random cells. Zero Width or Height fit the text with a margin of one cell.
+	Text string
+}
+
+// PicrGenerator makes random puzzles with a unique solution.
+type PicrGenerator struct {
+	opts PicrGenerateOptions
+	rnd  *rand.Rand
+	// Attempts is the total amount of grids tried so far.
+	Attempts uint
+}
+
+// ErrPicrGiveUp reports that no acceptable puzzle was found within the allowed attempts.
+var ErrPicrGiveUp = errors.New("PicrGenerator: no acceptable puzzle found")
+
+// NewPicrGenerator creates a generator of puzzles.
+func NewPicrGenerator(opts PicrGenerateOptions) (*PicrGenerator, error) {
+	if opts.Text != "" {
+		if opts.Symmetry != PicrIdentity {
+			return nil, errors.New("PicrGenerator: text cannot be symmetric")
+		}
+		if opts.Width == 0 {
+			opts.Width = uint(picrTextWidth(opts.Text)) + 2
+		}
+		if opts.Height == 0 {
+			opts.Height = picrGlyphHeight + 2
+		}
+		if opts.Width < uint(picrTextWidth(opts.Text)) || opts.Height < picrGlyphHeight {
+			return nil, fmt.Errorf("PicrGenerator: text %q does not fit in %dx%d", opts.Text, opts.Width, opts.Height)
+		}
+	}
+	if opts.Width < 1 || opts.Height < 1 {
+		return nil, fmt.Errorf("PicrGenerator: invalid size %dx%d", opts.Width, opts.Height)
+	}
+	if opts.Density < 0 || opts.Density > 1 {
+		return nil, fmt.Errorf("PicrGenerator: density %v out of range", opts.Density)
+	}
+	if opts.Clustering < 0 || opts.Clustering > 1 {
+		return nil, fmt.Errorf("PicrGenerator: clustering %v out of range", opts.Clustering)
+	}
+	if opts.Symmetry > PicrAntiTranspose || (opts.Symmetry&PicrTranspose != 0 && opts.Width != opts.Height) {
+		return nil, fmt.Errorf("PicrGenerator: symmetry %v does not apply to %dx%d", opts.Symmetry, opts.Width, opts.Height)
+	}
+	if opts.MaxFill == 0 {
+		if opts.Density == 0 && opts.MinFill == 0 {
+			opts.Density = 0.5
+		}
+		opts.MaxFill = 1
+	}
+	if opts.MinFill < 0 || opts.MaxFill > 1 || opts.MinFill > opts.MaxFill {
+		return nil, fmt.Errorf("PicrGenerator: fill band %v to %v out of range", opts.MinFill, opts.MaxFill)
+	}
+	if opts.MaxAttempts == 0 {
+		opts.MaxAttempts = 1000
+	}
+	return &PicrGenerator{opts: opts, rnd: rand.New(rand.NewSource(opts.Seed))}, nil
+}
+
+// Next returns a new puzzle, whose goal is its unique solution.
+func (g *PicrGenerator) Next() (*PicrPuzzle, error) {
+	for i := uint(0); i < g.opts.MaxAttempts; i++ {
+		g.Attempts += 1
+		p, err := NewPicrPuzzleFromGoal(g.grid())
+		if err != nil {
+			return nil, err
+		}
+		if g.acceptable(p) {
+			return p, nil
+		}
+	}
+	return nil, ErrPicrGiveUp
+}
+
+// acceptable tells whether a puzzle meets the structural constraints
+// and has a unique solution, reached by line logic if so requested.
+func (g *PicrGenerator) acceptable(p *PicrPuzzle) bool {
+	if !g.shapely(p) {
+		return false
+	}
+	_, err := picrPropagate(p, nil)
+	if err == nil {
+		return true
+	}
+	if g.opts.LineSolvable || isPicrContradiction(err) {
+		return false
+	}
+	r, err := p.Search(2)
+	return err == nil && len(r.Solutions) == 1
+}
+
+// shapely tells whether the goal of a puzzle meets the structural constraints of the generator.
+func (g *PicrGenerator) shapely(p *PicrPuzzle) bool {
+	filled := 0
+	for _, row := range p.Goal {
+		for _, v := range row {
+			if v == Fill {
+				filled += 1
+			}
+		}
+	}
+	fill := float64(filled) / float64(g.opts.Width*g.opts.Height)
+	if fill < g.opts.MinFill || fill > g.opts.MaxFill {
+		return false
+	}
+	if g.opts.MaxRuns > 0 {
+		for _, clues := range [][][]uint{p.RowClues, p.ColClues} {
+			for _, clue := range clues {
+				if uint(len(clue)) > g.opts.MaxRuns {
+					return false
+				}
+			}
+		}
+	}
+	return !g.opts.Connected || picrConnected(p.Goal)
+}
+
+// picrConnected tells whether the filled cells of a grid are all joined through their sides.
+func picrConnected(mat [][]CellState) bool {
+	seen := make([][]bool, len(mat))
+	for i := range seen {
+		seen[i] = make([]bool, len(mat[i]))
+	}
+	regions := 0
+	for i, row := range mat {
+		for j, v := range row {
+			if v != Fill || seen[i][j] {
+				continue
+			}
+			regions += 1
+			if regions > 1 {
+				return false
+			}
+			stack := [][2]int{{i, j}}
+			seen[i][j] = true
+			for len(stack) > 0 {
+				c := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+					y, x := c[0]+d[0], c[1]+d[1]
+					if y < 0 || y >= len(mat) || x < 0 || x >= len(mat[y]) || mat[y][x] != Fill || seen[y][x] {
+						continue
+					}
+					seen[y][x] = true
+					stack = append(stack, [2]int{y, x})
+				}
+			}
+		}
+	}
+	return true
+}
+
+// textGrid places the text of the generator at a random place of an empty grid.
+func (g *PicrGenerator) textGrid() [][]CellState {
+	text := picrTextGrid(g.opts.Text)
+	top := g.rnd.Intn(int(g.opts.Height) - len(text) + 1)
+	left := g.rnd.Intn(int(g.opts.Width) - len(text[0]) + 1)
+	return PicrPadGrid(text, uint(top), g.opts.Width-uint(left+len(text[0])), g.opts.Height-uint(top+len(text)), uint(left))
+}
+
+// picrSymmetryCell returns where a symmetry takes a cell of a square grid, or of any grid
+// when the symmetry involves no transposition.
+func picrSymmetryCell(i, j, height, width int, s PicrSymmetry) (int, int) {
+	if s&PicrTranspose != 0 {
+		i, j = j, i
+	}
+	if s&PicrMirrorH != 0 {
+		j = width - 1 - j
+	}
+	if s&PicrMirrorV != 0 {
+		i = height - 1 - i
+	}
+	return i, j
+}
+
+// picrSymmetrize replaces every value of a field by the mean over its orbit under a symmetry,
+// so that the field is left unchanged by the symmetry.
+func picrSymmetrize(field [][]float64, s PicrSymmetry) {
+	height, width := len(field), len(field[0])
+	done := make([][]bool, height)
+	for i := range done {
+		done[i] = make([]bool, width)
+	}
+	for i := range field {
+		for j := range field[i] {
+			if done[i][j] {
+				continue
+			}
+			orbit := [][2]int{{i, j}}
+			sum := field[i][j]
+			done[i][j] = true
+			for y, x := picrSymmetryCell(i, j, height, width, s); !done[y][x]; y, x = picrSymmetryCell(y, x, height, width, s) {
+				orbit = append(orbit, [2]int{y, x})
+				sum += field[y][x]
+				done[y][x] = true
+			}
+			for _, c := range orbit {
+				field[c[0]][c[1]] = sum / float64(len(orbit))
+			}
+		}
+	}
+}
+
+// grid makes a random grid with the requested density.
+// The cells with the highest values of a random field get filled,
+// the field being blurred beforehand for clustering and made symmetric if so requested.
+func (g *PicrGenerator) grid() [][]CellState {
+	if g.opts.Text != "" {
+		return g.textGrid()
+	}
+	width, height := int(g.opts.Width), int(g.opts.Height)
+	field := make([][]float64, height)
+	for i := range field {
+		field[i] = make([]float64, width)
+		for j := range field[i] {
+			field[i][j] = g.rnd.Float64()
+		}
+	}
+	passes := int(g.opts.Clustering*4 + 0.5)
+	for k := 0; k < passes; k++ {
+		field = picrBlur(field)
+	}
+	if g.opts.Symmetry != PicrIdentity {
+		picrSymmetrize(field, g.opts.Symmetry)
+	}
+	type cell struct {
+		i, j int
+		v    float64
+	}
+	cells := make([]cell, 0, width*height)
+	for i, row := range field {
+		for j, v := range row {
+			cells = append(cells, cell{i, j, v})
+		}
+	}
+	sort.Slice(cells, func(a, b int) bool { return cells[a].v > cells[b].v })
+	ans := make([][]CellState, height)
+	for i := range ans {
+		ans[i] = make([]CellState, width)
+		for j := range ans[i] {
+			ans[i][j] = Gap
+		}
+	}
+	density := g.opts.Density
+	if density == 0 {
+		density = g.opts.MinFill + g.rnd.Float64()*(g.opts.MaxFill-g.opts.MinFill)
+	}
+	filled := int(density*float64(len(cells)) + 0.5)
+	// Cells of the same orbit share their value, and get filled together.
+	for k, c := range cells {
+		if k >= filled && (k == 0 || c.v != cells[k-1].v) {
+			break
+		}
+		ans[c.i][c.j] = Fill
+	}
+	return ans
+}
+
+// picrBlur averages every value of a field with its neighbours.
+func picrBlur(field [][]float64) [][]float64 {
+	ans := make([][]float64, len(field))
+	for i := range field {
+		ans[i] = make([]float64, len(field[i]))
+		for j := range field[i] {
+			var sum float64
+			var n int
+			for di := -1; di <= 1; di++ {
+				for dj := -1; dj <= 1; dj++ {
+					y, x := i+di, j+dj
+					if y < 0 || y >= len(field) || x < 0 || x >= len(field[y]) {
+						continue
+					}
+					sum += field[y][x]
+					n += 1
+				}
+			}
+			ans[i][j] = sum / float64(n)
+		}
+	}
+	return ans
+}
@@ -0,0 +1,169 @@
+package picross
+
+import (
+	"testing"
+)
+
+func TestPicrGenerator(t *testing.T) {
+	opts := PicrGenerateOptions{Width: 8, Height: 6, Density: 0.6, Seed: 42}
+	g, err := NewPicrGenerator(opts)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	var first []*PicrPuzzle
+	for i := 0; i < 3; i++ {
+		p, err := g.Next()
+		if err != nil {
+			t.Fatalf(`unexpected error: %v`, err)
+		}
+		if p.Width() != 8 || p.Height() != 6 {
+			t.Fatalf(`unexpected size: %vx%v`, p.Width(), p.Height())
+		}
+		filled := 0
+		for _, row := range p.Goal {
+			for _, v := range row {
+				if v == Fill {
+					filled += 1
+				}
+			}
+		}
+		if filled != 29 {
+			t.Errorf(`unexpected amount of filled cells: %v`, filled)
+		}
+		r, err := p.Search(2)
+		if err != nil || len(r.Solutions) != 1 || !areSlices2Equal(r.Solutions[0], p.Goal) {
+			t.Errorf(`puzzle without a unique solution: %v %v`, r, err)
+		}
+		first = append(first, p)
+	}
+	if g.Attempts < 3 {
+		t.Errorf(`unexpected attempts: %v`, g.Attempts)
+	}
+	g, _ = NewPicrGenerator(opts)
+	for i := range first {
+		if p, _ := g.Next(); !areSlices2Equal(p.Goal, first[i].Goal) {
+			t.Errorf(`puzzle %v not reproduced`, i)
+		}
+	}
+}
+
+func TestPicrGeneratorLineSolvable(t *testing.T) {
+	g, _ := NewPicrGenerator(PicrGenerateOptions{Width: 10, Height: 10, Clustering: 1, LineSolvable: true, Seed: 7})
+	for i := 0; i < 3; i++ {
+		p, err := g.Next()
+		if err != nil {
+			t.Fatalf(`unexpected error: %v`, err)
+		}
+		if _, err := picrPropagate(p, nil); err != nil {
+			t.Errorf(`puzzle not solved by line logic: %v`, err)
+		}
+	}
+}
+
+func TestPicrGeneratorShapes(t *testing.T) {
+	for _, opts := range []PicrGenerateOptions{
+		{Width: 8, Height: 6, Symmetry: PicrMirrorH, Clustering: 0.5},
+		{Width: 7, Height: 7, Symmetry: PicrRotate90, Clustering: 0.5},
+		{Width: 6, Height: 6, Symmetry: PicrTranspose},
+		{Width: 8, Height: 8, Connected: true, Clustering: 1, Density: 0.4},
+		{Width: 8, Height: 8, MaxRuns: 2, Clustering: 1},
+		{Width: 8, Height: 8, MinFill: 0.3, MaxFill: 0.4},
+	} {
+		g, err := NewPicrGenerator(opts)
+		if err != nil {
+			t.Fatalf(`%+v: unexpected error: %v`, opts, err)
+		}
+		for i := 0; i < 3; i++ {
+			p, err := g.Next()
+			if err != nil {
+				t.Fatalf(`%+v: unexpected error: %v`, opts, err)
+			}
+			if !areSlices2Equal(PicrTransformGrid(p.Goal, opts.Symmetry), p.Goal) {
+				t.Errorf(`%+v: goal not symmetric: %v`, opts, p.Goal)
+			}
+			if !g.shapely(p) {
+				t.Errorf(`%+v: goal out of shape: %v`, opts, p.Goal)
+			}
+		}
+	}
+}
+
+func TestPicrGeneratorText(t *testing.T) {
+	g, err := NewPicrGenerator(PicrGenerateOptions{Text: `Hi`, Seed: 1})
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	p, err := g.Next()
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	// The text lands somewhere within a margin of one cell.
+	text := str2Map(`#.#.###
+                     #.#..#.
+                     ###..#.
+                     #.#..#.
+                     #.#.###`)
+	found := false
+	for top := uint(0); top <= 2; top++ {
+		for left := uint(0); left <= 2; left++ {
+			found = found || areSlices2Equal(p.Goal, PicrPadGrid(text, top, 2-left, 2-top, left))
+		}
+	}
+	if !found || p.Width() != 9 || p.Height() != 7 {
+		t.Errorf(`unexpected goal: %v`, p.Goal)
+	}
+	g, _ = NewPicrGenerator(PicrGenerateOptions{Text: `7`, Width: 6, Height: 6, Seed: 1})
+	if p, err = g.Next(); err != nil || p.Width() != 6 || p.Height() != 6 {
+		t.Fatalf(`unexpected result: %v %v`, p, err)
+	}
+}
+
+func TestPicrConnected(t *testing.T) {
+	if !picrConnected(str2Map(`##.
+                               .#.
+                               .##`)) {
+		t.Errorf(`connected grid not recognized`)
+	}
+	if picrConnected(str2Map(`#..
+                              .#.
+                              ..#`)) {
+		t.Errorf(`disconnected grid not recognized`)
+	}
+}
+
+func TestPicrGeneratorFail(t *testing.T) {
+	for _, opts := range []PicrGenerateOptions{
+		{Width: 0, Height: 5},
+		{Width: 5, Height: 5, Density: 1.5},
+		{Width: 5, Height: 5, Clustering: -1},
+		{Width: 5, Height: 4, Symmetry: PicrRotate90},
+		{Width: 5, Height: 5, Symmetry: 8},
+		{Width: 5, Height: 5, MinFill: 0.6, MaxFill: 0.4},
+		{Text: `HI`, Symmetry: PicrMirrorH},
+		{Text: `HELLO`, Width: 10},
+	} {
+		if _, err := NewPicrGenerator(opts); err == nil {
+			t.Errorf(`%+v: unexpected success`, opts)
+		}
+	}
+	// With a single attempt, a diagonal pair of cells in a 2x2 grid is eventually drawn and rejected.
+	gaveUp := false
+	for seed := int64(0); seed < 20; seed++ {
+		g, _ := NewPicrGenerator(PicrGenerateOptions{Width: 2, Height: 2, Seed: seed, MaxAttempts: 1})
+		_, err := g.Next()
+		if err != nil && err != ErrPicrGiveUp {
+			t.Errorf(`unexpected error: %v`, err)
+		}
+		gaveUp = gaveUp || err == ErrPicrGiveUp
+	}
+	if !gaveUp {
+		t.Errorf(`ambiguous puzzle never rejected`)
+	}
+}
+
+func TestPicrBlur(t *testing.T) {
+	got := picrBlur([][]float64{{0, 3}, {6, 3}})
+	if !areSlices2Equal(got, [][]float64{{3, 3}, {3, 3}}) {
+		t.Errorf(`unexpected blur: %v`, got)
+	}
+}
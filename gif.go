@@ -0,0 +1,173 @@
+package picross
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+)
+
+// PicrGIFOptions controls the animation of a solving process.
+type PicrGIFOptions struct {
+	// CellSize is the side of a cell, in pixels. Zero selects 8.
+	CellSize int
+	// CellsPerFrame, when not zero, adds a frame every time that many cells get determined.
+	// Zero adds a frame per solver round.
+	CellsPerFrame uint
+	// Delay is the time between frames, in hundredths of a second. Zero selects 10.
+	Delay int
+	// FinalDelay is the time the last frame is shown, in hundredths of a second. Zero selects 200.
+	FinalDelay int
+	// Colors of the animation; nil selects the defaults.
+	AnyColor  color.Color
+	FillColor color.Color
+	GapColor  color.Color
+	GridColor color.Color
+}
+
+// Palette indexes of the frames.
+const (
+	picrGIFAny uint8 = iota
+	picrGIFFill
+	picrGIFGap
+	picrGIFGrid
+)
+
+// PicrGIFRecorder builds an animated GIF of a grid being determined,
+// fed by the notifications of a PicrSolver.
+type PicrGIFRecorder struct {
+	opts    PicrGIFOptions
+	palette color.Palette
+	state   [][]CellState
+	anim    gif.GIF
+	pending uint
+}
+
+// NewPicrGIFRecorder creates a recorder for a grid of a given size,
+// starting with a frame of the empty grid.
+func NewPicrGIFRecorder(width, height uint, opts PicrGIFOptions) *PicrGIFRecorder {
+	if opts.CellSize < 1 {
+		opts.CellSize = 8
+	}
+	if opts.Delay < 1 {
+		opts.Delay = 10
+	}
+	if opts.FinalDelay < 1 {
+		opts.FinalDelay = 200
+	}
+	r := &PicrGIFRecorder{
+		opts: opts,
+		palette: color.Palette{
+			picrColorOr(opts.AnyColor, picrPaperColor),
+			picrColorOr(opts.FillColor, picrInkColor),
+			picrColorOr(opts.GapColor, color.RGBA{0xdd, 0xdd, 0xdd, 0xff}),
+			picrColorOr(opts.GridColor, color.RGBA{0x99, 0x99, 0x99, 0xff}),
+		},
+		state: make([][]CellState, height),
+	}
+	for i := range r.state {
+		r.state[i] = make([]CellState, width)
+	}
+	r.Frame()
+	return r
+}
+
+// picrColorOr returns `c`, or `def` when `c` is nil.
+func picrColorOr(c color.Color, def color.Color) color.Color {
+	if c == nil {
+		return def
+	}
+	return c
+}
+
+// Record applies a notification to the grid,
+// adding a frame when CellsPerFrame cells have been determined since the last one.
+func (r *PicrGIFRecorder) Record(n PicrSolverNotification) {
+	if n.row < 1 || n.row > uint(len(r.state)) || n.col < 1 || n.col > uint(len(r.state[0])) {
+		return
+	}
+	r.state[n.row-1][n.col-1] = Gap
+	if n.mark {
+		r.state[n.row-1][n.col-1] = Fill
+	}
+	r.pending += 1
+	if r.opts.CellsPerFrame > 0 && r.pending >= r.opts.CellsPerFrame {
+		r.Frame()
+	}
+}
+
+// EndRound marks the end of a solver round, adding a frame when framing by rounds.
+func (r *PicrGIFRecorder) EndRound() {
+	if r.opts.CellsPerFrame == 0 && r.pending > 0 {
+		r.Frame()
+	}
+}
+
+// Frame adds a frame with the current grid.
+func (r *PicrGIFRecorder) Frame() {
+	r.pending = 0
+	size := r.opts.CellSize
+	height, width := len(r.state), len(r.state[0])
+	img := image.NewPaletted(image.Rect(0, 0, width*(size+1)+1, height*(size+1)+1), r.palette)
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			if x%(size+1) == 0 || y%(size+1) == 0 {
+				img.SetColorIndex(x, y, picrGIFGrid)
+				continue
+			}
+			switch r.state[y/(size+1)][x/(size+1)] {
+			case Fill:
+				img.SetColorIndex(x, y, picrGIFFill)
+			case Gap:
+				img.SetColorIndex(x, y, picrGIFGap)
+			}
+		}
+	}
+	r.anim.Image = append(r.anim.Image, img)
+	r.anim.Delay = append(r.anim.Delay, r.opts.Delay)
+}
+
+// Encode writes the animation, flushing any cells not yet shown in a frame.
+func (r *PicrGIFRecorder) Encode(w io.Writer) error {
+	if r.pending > 0 {
+		r.Frame()
+	}
+	r.anim.Delay[len(r.anim.Delay)-1] = r.opts.FinalDelay
+	return gif.EncodeAll(w, &r.anim)
+}
+
+// RecordPicrSolveGIF solves a puzzle and writes an animation of the process.
+// The animation is written even if the solver fails, showing how far it got;
+// the solver error is returned afterwards.
+func RecordPicrSolveGIF(w io.Writer, p *PicrPuzzle, opts PicrGIFOptions) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	// Every cell is notified at most once, so the channel never blocks the solver.
+	ch := make(chan PicrSolverNotification, p.Width()*p.Height())
+	s, err := p.NewSolver(ch)
+	if err != nil {
+		return err
+	}
+	r := NewPicrGIFRecorder(p.Width(), p.Height(), opts)
+	consume := func() {
+		for {
+			select {
+			case n := <-ch:
+				r.Record(n)
+			default:
+				return
+			}
+		}
+	}
+	s.roundHook = func() {
+		consume()
+		r.EndRound()
+	}
+	solveErr := s.solve()
+	consume()
+	if err := r.Encode(w); err != nil {
+		return err
+	}
+	return solveErr
+}
@@ -0,0 +1,64 @@
+package picross
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+)
+
+func TestRecordPicrSolveGIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RecordPicrSolveGIF(&buf, horsePuzzle(), PicrGIFOptions{CellSize: 4}); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if len(anim.Image) < 2 {
+		t.Fatalf(`unexpected amount of frames: %v`, len(anim.Image))
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != 26 || b.Dy() != 26 {
+		t.Errorf(`unexpected frame size: %v`, b)
+	}
+	if d := anim.Delay[len(anim.Delay)-1]; d != 200 {
+		t.Errorf(`unexpected final delay: %v`, d)
+	}
+	first, last := anim.Image[0], anim.Image[len(anim.Image)-1]
+	// Center of the first cell (filled in the solution).
+	if first.ColorIndexAt(2, 2) != picrGIFAny || last.ColorIndexAt(2, 2) != picrGIFFill {
+		t.Errorf(`unexpected first cell colors: %v %v`, first.ColorIndexAt(2, 2), last.ColorIndexAt(2, 2))
+	}
+	// Center of the last cell (a gap in the solution).
+	if last.ColorIndexAt(22, 22) != picrGIFGap {
+		t.Errorf(`unexpected last cell color: %v`, last.ColorIndexAt(22, 22))
+	}
+}
+
+func TestPicrGIFRecorderCellsPerFrame(t *testing.T) {
+	r := NewPicrGIFRecorder(2, 2, PicrGIFOptions{CellsPerFrame: 2})
+	r.Record(PicrSolverNotification{1, 1, true})
+	r.EndRound()
+	r.Record(PicrSolverNotification{1, 2, false})
+	r.Record(PicrSolverNotification{2, 1, false})
+	r.Record(PicrSolverNotification{2, 2, true})
+	var buf bytes.Buffer
+	if err := r.Encode(&buf); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	anim, _ := gif.DecodeAll(&buf)
+	if len(anim.Image) != 3 {
+		t.Errorf(`unexpected amount of frames: %v`, len(anim.Image))
+	}
+}
+
+func TestRecordPicrSolveGIFFail(t *testing.T) {
+	var buf bytes.Buffer
+	p := &PicrPuzzle{RowClues: [][]uint{{1}, {1}}, ColClues: [][]uint{{1}, {1}}}
+	if err := RecordPicrSolveGIF(&buf, p, PicrGIFOptions{}); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+	if _, err := gif.DecodeAll(&buf); err != nil {
+		t.Errorf(`animation not written: %v`, err)
+	}
+}
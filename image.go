@@ -0,0 +1,218 @@
+package picross
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// PicrImageOptions controls how an image is turned into a puzzle.
+type PicrImageOptions struct {
+	// Threshold is the luminance, from 0 (black) to 1 (white), below which a pixel becomes a filled cell.
+	// Zero selects 0.5.
+	Threshold float64
+	// Dither enables Floyd–Steinberg error diffusion while thresholding.
+	Dither bool
+	// Width and Height, when not zero, resize the image to the target amount of cells.
+	// When only one of them is given, the other one follows the aspect ratio of the image.
+	Width  uint
+	Height uint
+}
+
+// PicrImageMaxPixels is the largest amount of pixels of the images decoded by this package;
+// larger images are refused from their header, before any pixel memory is allocated.
+const PicrImageMaxPixels = 1 << 26
+
+// checkPicrImageSize refuses image dimensions beyond PicrImageMaxPixels.
+func checkPicrImageSize(width, height int) error {
+	if width > PicrImageMaxPixels || height > PicrImageMaxPixels || width*height > PicrImageMaxPixels {
+		return fmt.Errorf("%dx%d image exceeds %d pixels", width, height, PicrImageMaxPixels)
+	}
+	return nil
+}
+
+// ReadPicrImage decodes an image of any registered format, after checking from its header
+// that it does not exceed PicrImageMaxPixels.
+func ReadPicrImage(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	// The header of the formats in use fits in the buffer, so that peeking it leaves the reader untouched.
+	head, err := br.Peek(br.Size())
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(head))
+	if err == nil {
+		if err := checkPicrImageSize(cfg.Width, cfg.Height); err != nil {
+			return nil, fmt.Errorf("PicrImage: %v", err)
+		}
+	}
+	img, _, err := image.Decode(br)
+	return img, err
+}
+
+// DecodePicrImage decodes a PNG, PBM or PGM image, as ReadPicrImage does,
+// and derives from it a puzzle whose goal is the thresholded image.
+func DecodePicrImage(r io.Reader, opts PicrImageOptions) (*PicrPuzzle, error) {
+	img, err := ReadPicrImage(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewPicrPuzzleFromImage(img, opts)
+}
+
+// encodePicrPNG writes the goal of a puzzle as a PNG image of one black or white pixel per cell,
+// which DecodePicrImage reads back into the same puzzle.
+func encodePicrPNG(w io.Writer, p *PicrPuzzle) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	if p.Goal == nil {
+		return errors.New("PicrImage: puzzle has no goal")
+	}
+	img := image.NewGray(image.Rect(0, 0, int(p.Width()), int(p.Height())))
+	for i, row := range p.Goal {
+		for j, v := range row {
+			c := color.Gray{Y: 0xff}
+			if v == Fill {
+				c.Y = 0
+			}
+			img.SetGray(j, i, c)
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// NewPicrPuzzleFromImage derives a puzzle from an image.
+// The returned puzzle carries the goal grid, so that its uniqueness can be verified by a PicrSolver.
+func NewPicrPuzzleFromImage(img image.Image, opts PicrImageOptions) (*PicrPuzzle, error) {
+	lum := picrImageLuminance(img)
+	if len(lum) < 1 || len(lum[0]) < 1 {
+		return nil, errors.New("PicrImage: empty image")
+	}
+	width, height := picrImageTargetSize(uint(len(lum[0])), uint(len(lum)), opts.Width, opts.Height)
+	lum = picrResample(lum, width, height)
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = 0.5
+	}
+	return NewPicrPuzzleFromGoal(picrThreshold(lum, threshold, opts.Dither))
+}
+
+// picrImageLuminance returns the luminance of each pixel of an image, from 0 to 1, indexed by row and then by column.
+// Transparent pixels are composed over a white background.
+func picrImageLuminance(img image.Image) [][]float64 {
+	b := img.Bounds()
+	ans := make([][]float64, b.Dy())
+	for y := range ans {
+		ans[y] = make([]float64, b.Dx())
+		for x := range ans[y] {
+			r, g, bl, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			// Premultiplied components: add the white background behind the missing alpha.
+			white := float64(0xffff - a)
+			lum := 0.299*(float64(r)+white) + 0.587*(float64(g)+white) + 0.114*(float64(bl)+white)
+			ans[y][x] = lum / 0xffff
+		}
+	}
+	return ans
+}
+
+// picrImageTargetSize computes the size of the grid for a source image,
+// honoring the requested width and height, where zero means unconstrained.
+func picrImageTargetSize(srcWidth, srcHeight, width, height uint) (uint, uint) {
+	switch {
+	case width == 0 && height == 0:
+		return srcWidth, srcHeight
+	case height == 0:
+		height = (srcHeight*width + srcWidth/2) / srcWidth
+	case width == 0:
+		width = (srcWidth*height + srcHeight/2) / srcHeight
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	return width, height
+}
+
+// picrResample resizes a luminance map by averaging the source pixels covered by each target pixel.
+func picrResample(lum [][]float64, width, height uint) [][]float64 {
+	srcHeight, srcWidth := uint(len(lum)), uint(len(lum[0]))
+	if srcWidth == width && srcHeight == height {
+		return lum
+	}
+	ans := make([][]float64, height)
+	for y := uint(0); y < height; y++ {
+		y0, y1 := picrResampleSpan(y, height, srcHeight)
+		ans[y] = make([]float64, width)
+		for x := uint(0); x < width; x++ {
+			x0, x1 := picrResampleSpan(x, width, srcWidth)
+			var sum float64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					sum += lum[sy][sx]
+				}
+			}
+			ans[y][x] = sum / float64((y1-y0)*(x1-x0))
+		}
+	}
+	return ans
+}
+
+// picrResampleSpan returns the range of source positions covered by target position `i`,
+// which is never empty.
+func picrResampleSpan(i, size, srcSize uint) (uint, uint) {
+	start := i * srcSize / size
+	end := (i + 1) * srcSize / size
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
+
+// picrThreshold converts a luminance map into a grid, where dark pixels are filled cells.
+// When `dither` is set the quantization error is diffused to the neighbouring pixels (Floyd–Steinberg).
+func picrThreshold(lum [][]float64, threshold float64, dither bool) [][]CellState {
+	height, width := len(lum), len(lum[0])
+	work := make([][]float64, height)
+	for y := range work {
+		work[y] = make([]float64, width)
+		copy(work[y], lum[y])
+	}
+	ans := make([][]CellState, height)
+	for y := 0; y < height; y++ {
+		ans[y] = make([]CellState, width)
+		for x := 0; x < width; x++ {
+			v := work[y][x]
+			quantized := 1.0
+			ans[y][x] = Gap
+			if v < threshold {
+				quantized = 0.0
+				ans[y][x] = Fill
+			}
+			if !dither {
+				continue
+			}
+			e := v - quantized
+			if x+1 < width {
+				work[y][x+1] += e * 7 / 16
+			}
+			if y+1 < height {
+				if x > 0 {
+					work[y+1][x-1] += e * 3 / 16
+				}
+				work[y+1][x] += e * 5 / 16
+				if x+1 < width {
+					work[y+1][x+1] += e * 1 / 16
+				}
+			}
+		}
+	}
+	return ans
+}
@@ -0,0 +1,107 @@
+package picross
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestDecodePicrImage(t *testing.T) {
+	// 5x5 horse
+	pbm := `P1 5 5
+11100
+01001
+01111
+01110
+01010`
+	p, err := DecodePicrImage(bytes.NewBufferString(pbm), PicrImageOptions{})
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	expRows := [][]uint{{3}, {1, 1}, {4}, {3}, {1, 1}}
+	expCols := [][]uint{{1}, {5}, {1, 2}, {3}, {2}}
+	if !areSlices2Equal(p.RowClues, expRows) || !areSlices2Equal(p.ColClues, expCols) {
+		t.Errorf(`clues mismatch: expected %v %v, got %v %v`, expRows, expCols, p.RowClues, p.ColClues)
+	}
+	s, _ := p.NewSolver(nil)
+	if err := s.solve(); err != nil {
+		t.Fatalf(`unexpected solver error: %v`, err)
+	}
+	if !areSlices2Equal(s.getState(), p.Goal) {
+		t.Errorf(`solution mismatch: expected %v, got %v`, p.Goal, s.getState())
+	}
+}
+
+func TestDecodePicrImageDownscale(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 8; x++ {
+			c := color.NRGBA{255, 255, 255, 255}
+			if x < 4 && y < 2 {
+				c = color.NRGBA{0, 0, 0, 255}
+			}
+			if x >= 6 {
+				// Transparent black counts as white.
+				c = color.NRGBA{0, 0, 0, 0}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf(`%v`, err)
+	}
+	p, err := DecodePicrImage(&buf, PicrImageOptions{Width: 4})
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	expected := str2Map(`##..
+                         ....`)
+	if !areSlices2Equal(p.Goal, expected) {
+		t.Errorf(`goal mismatch: expected %v, got %v`, expected, p.Goal)
+	}
+}
+
+func TestPicrThresholdDither(t *testing.T) {
+	lum := make([][]float64, 8)
+	for y := range lum {
+		lum[y] = make([]float64, 8)
+		for x := range lum[y] {
+			lum[y][x] = 0.5
+		}
+	}
+	if n := picrCountAny(picrThreshold(lum, 0.5, false)); n != 0 {
+		t.Errorf(`unexpected undetermined cells: %v`, n)
+	}
+	var fills int
+	for _, row := range picrThreshold(lum, 0.5, true) {
+		for _, v := range row {
+			if v == Fill {
+				fills += 1
+			}
+		}
+	}
+	if fills < 24 || fills > 40 {
+		t.Errorf(`unexpected dithered fill count: %v`, fills)
+	}
+}
+
+func TestReadPicrImageTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+	data := buf.Bytes()
+	// Claim a 50000x50000 picture in the IHDR chunk, with a matching checksum.
+	binary.BigEndian.PutUint32(data[16:], 50000)
+	binary.BigEndian.PutUint32(data[20:], 50000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	for _, input := range [][]byte{data, []byte("P5 9999999 9999999 255")} {
+		if _, err := ReadPicrImage(bytes.NewReader(input)); err == nil || !strings.Contains(err.Error(), `exceeds`) {
+			t.Errorf(`%q: unexpected error: %v`, input[:8], err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package picross
+
+// PicrMetrics holds objective measures of a puzzle, apart from its difficulty.
+type PicrMetrics struct {
+	Width  uint `json:"width"`
+	Height uint `json:"height"`
+	// Density is the fraction of filled cells, as told by the clues.
+	Density float64 `json:"density"`
+	// Clues is the amount of runs in the clues of all lines, and MeanRun their mean length.
+	Clues   uint    `json:"clues"`
+	MeanRun float64 `json:"mean_run"`
+	// TrivialLines is the amount of lines whose runs, with a gap between each other, fill the whole line.
+	// EmptyLines is the amount of lines without runs.
+	TrivialLines uint `json:"trivial_lines"`
+	EmptyLines   uint `json:"empty_lines"`
+	// FirstRound is the amount of cells determined by the first round of line logic, over columns and then rows.
+	FirstRound uint `json:"first_round"`
+	// Rounds is the amount of rounds of line logic that determined some cell.
+	Rounds uint `json:"rounds"`
+	// LineSolved tells whether line logic alone determines every cell.
+	LineSolved bool `json:"line_solved"`
+	// Order tells, for every cell, the round of line logic that determined it, or 0 if none did.
+	Order [][]uint `json:"order"`
+}
+
+// Metrics measures a puzzle from its clues and from a run of line logic over it.
+// It fails when the clues are invalid or contradict each other.
+func (p *PicrPuzzle) Metrics() (*PicrMetrics, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	m := &PicrMetrics{Width: p.Width(), Height: p.Height()}
+	var filled, runs uint
+	for k, clues := range [][][]uint{p.RowClues, p.ColClues} {
+		length := p.Width()
+		if k > 0 {
+			length = p.Height()
+		}
+		for _, clue := range clues {
+			span := picrClueSpan(clue)
+			switch {
+			case span == 0:
+				m.EmptyLines += 1
+			case span == length:
+				m.TrivialLines += 1
+			}
+			for _, v := range clue {
+				if v == 0 {
+					continue
+				}
+				m.Clues += 1
+				runs += v
+				if k == 0 {
+					filled += v
+				}
+			}
+		}
+	}
+	m.Density = float64(filled) / float64(p.Width()*p.Height())
+	if m.Clues > 0 {
+		m.MeanRun = float64(runs) / float64(m.Clues)
+	}
+	m.Order = make([][]uint, p.Height())
+	for i := range m.Order {
+		m.Order[i] = make([]uint, p.Width())
+	}
+	s, err := p.NewSolver(nil)
+	if err != nil {
+		return nil, err
+	}
+	round := uint(0)
+	s.roundHook = func() {
+		round += 1
+		determined := false
+		for i, row := range s.getState() {
+			for j, v := range row {
+				if v != Any && m.Order[i][j] == 0 {
+					m.Order[i][j] = round
+					determined = true
+					if round == 1 {
+						m.FirstRound += 1
+					}
+				}
+			}
+		}
+		if determined {
+			m.Rounds = round
+		}
+	}
+	err = s.solve()
+	if isPicrContradiction(err) {
+		return nil, err
+	}
+	m.LineSolved = err == nil
+	return m, nil
+}
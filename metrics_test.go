@@ -0,0 +1,43 @@
+package picross
+
+import (
+	"testing"
+)
+
+func TestPicrMetrics(t *testing.T) {
+	m, err := horsePuzzle().Metrics()
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if m.Width != 5 || m.Height != 5 || m.Density != 0.56 || m.Clues != 13 || m.MeanRun != 28.0/13 {
+		t.Errorf(`unexpected clue metrics: %+v`, m)
+	}
+	if m.TrivialLines != 1 || m.EmptyLines != 0 {
+		t.Errorf(`unexpected line metrics: %+v`, m)
+	}
+	if m.FirstRound != 15 || m.Rounds != 3 || !m.LineSolved {
+		t.Errorf(`unexpected solver metrics: %+v`, m)
+	}
+	expected := [][]uint{{3, 1, 1, 3, 1}, {1, 1, 1, 2, 2}, {2, 1, 1, 1, 2}, {3, 1, 1, 3, 1}, {1, 1, 1, 2, 2}}
+	if !areSlices2Equal(m.Order, expected) {
+		t.Errorf(`unexpected order: %v`, m.Order)
+	}
+}
+
+func TestPicrMetricsStalled(t *testing.T) {
+	m, err := probePuzzle().Metrics()
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if m.LineSolved || m.Rounds != 6 || m.Order[2][3] != 0 {
+		t.Errorf(`unexpected metrics: %+v`, m)
+	}
+	empty := &PicrPuzzle{RowClues: [][]uint{{}, {2}}, ColClues: [][]uint{{1}, {1}}}
+	if m, err = empty.Metrics(); err != nil || m.EmptyLines != 1 || m.TrivialLines != 1 {
+		t.Errorf(`unexpected metrics: %+v %v`, m, err)
+	}
+	bad := &PicrPuzzle{RowClues: [][]uint{{1}}, ColClues: [][]uint{{0}}}
+	if _, err := bad.Metrics(); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
@@ -0,0 +1,166 @@
+package picross
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseNon reads a puzzle in Steve Simpson's `.non` format.
+// Recognized keywords are `title`, `width`, `height`, `rows`, `columns` and `goal`;
+// other keywords are ignored.
+// The clues follow `rows` and `columns`, one comma separated line per row (column),
+// up to the next blank line or keyword, where '0' denotes an empty line.
+func ParseNon(r io.Reader) (*PicrPuzzle, error) {
+	sc := bufio.NewScanner(r)
+	var lineNo int
+	var width, height int
+	var rows, cols [][]uint
+	var goal string
+	p := &PicrPuzzle{}
+	var section *[][]uint
+	for sc.Scan() {
+		lineNo += 1
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			section = nil
+			continue
+		}
+		if section != nil && line[0] >= '0' && line[0] <= '9' {
+			clue, err := parseNonClue(line)
+			if err != nil {
+				return nil, fmt.Errorf("non: line %d: %v", lineNo, err)
+			}
+			*section = append(*section, clue)
+			continue
+		}
+		section = nil
+		keyword, arg := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			keyword, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		var err error
+		switch keyword {
+		case "title":
+			p.Title = unquoteNon(arg)
+		case "width":
+			width, err = strconv.Atoi(arg)
+		case "height":
+			height, err = strconv.Atoi(arg)
+		case "rows":
+			section = &rows
+		case "columns":
+			section = &cols
+		case "goal":
+			goal = unquoteNon(arg)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("non: line %d: invalid %s", lineNo, keyword)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(rows) != height || len(cols) != width {
+		return nil, fmt.Errorf("non: expected %d rows and %d columns, got %d and %d", height, width, len(rows), len(cols))
+	}
+	p.RowClues, p.ColClues = rows, cols
+	if goal != "" {
+		if len(goal) != width*height {
+			return nil, fmt.Errorf("non: goal has %d cells, expected %d", len(goal), width*height)
+		}
+		p.Goal = make([][]CellState, height)
+		for i := range p.Goal {
+			p.Goal[i] = make([]CellState, width)
+			for j := range p.Goal[i] {
+				switch goal[i*width+j] {
+				case '0':
+					p.Goal[i][j] = Gap
+				case '1':
+					p.Goal[i][j] = Fill
+				default:
+					return nil, errors.New("non: goal must contain only '0' and '1'")
+				}
+			}
+		}
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FormatNon writes a puzzle in the `.non` format.
+func FormatNon(w io.Writer, p *PicrPuzzle) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	if p.Title != "" {
+		fmt.Fprintf(bw, "title %s\n", strconv.Quote(p.Title))
+	}
+	fmt.Fprintf(bw, "width %d\nheight %d\n\nrows\n", p.Width(), p.Height())
+	for _, clue := range p.RowClues {
+		fmt.Fprintln(bw, formatNonClue(clue))
+	}
+	fmt.Fprintf(bw, "\ncolumns\n")
+	for _, clue := range p.ColClues {
+		fmt.Fprintln(bw, formatNonClue(clue))
+	}
+	if p.Goal != nil {
+		var b strings.Builder
+		for _, row := range p.Goal {
+			for _, v := range row {
+				if v == Fill {
+					b.WriteByte('1')
+				} else {
+					b.WriteByte('0')
+				}
+			}
+		}
+		fmt.Fprintf(bw, "\ngoal %s\n", strconv.Quote(b.String()))
+	}
+	return bw.Flush()
+}
+
+// parseNonClue decodes the comma separated run lengths of a line.
+func parseNonClue(s string) ([]uint, error) {
+	ans := make([]uint, 0)
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.ParseUint(strings.TrimSpace(field), 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run length %q", field)
+		}
+		if v == 0 {
+			continue
+		}
+		ans = append(ans, uint(v))
+	}
+	return ans, nil
+}
+
+// formatNonClue encodes the run lengths of a line.
+func formatNonClue(clue []uint) string {
+	fields := make([]string, 0, len(clue))
+	for _, v := range clue {
+		if v == 0 {
+			continue
+		}
+		fields = append(fields, strconv.FormatUint(uint64(v), 10))
+	}
+	if len(fields) == 0 {
+		return "0"
+	}
+	return strings.Join(fields, ",")
+}
+
+// unquoteNon strips the optional double quotes around a keyword argument.
+func unquoteNon(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return strings.Trim(s, `"`)
+}
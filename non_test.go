@@ -0,0 +1,67 @@
+package picross
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseNon(t *testing.T) {
+	non := `catalogue "test"
+title "Horse"
+by "someone"
+width 5
+height 5
+
+rows
+3
+1,1
+4
+3
+1, 1
+
+columns
+1
+5
+1,2
+3
+2
+
+goal "1110001001011110111001010"
+`
+	p, err := ParseNon(strings.NewReader(non))
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	h := horsePuzzle()
+	if p.Title != `Horse` || !areSlices2Equal(p.RowClues, h.RowClues) || !areSlices2Equal(p.ColClues, h.ColClues) {
+		t.Errorf(`puzzle mismatch: %v`, p)
+	}
+	if p.Goal == nil || picrFormatRow(p.Goal[1]) != `.#..#` {
+		t.Errorf(`goal mismatch: %v`, p.Goal)
+	}
+	var buf bytes.Buffer
+	if err := FormatNon(&buf, p); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	q, err := ParseNon(&buf)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if q.Title != p.Title || !areSlices2Equal(q.RowClues, p.RowClues) || !areSlices2Equal(q.Goal, p.Goal) {
+		t.Errorf(`round trip mismatch: %v`, q)
+	}
+}
+
+func TestParseNonFail(t *testing.T) {
+	for _, non := range []string{
+		"width 2\nheight 1\nrows\n1\ncolumns\n1\n",
+		"width 1\nheight 1\nrows\nx\ncolumns\n1\n",
+		"width 1\nheight 1\nrows\n1\ncolumns\n1\ngoal 10\n",
+		"width a\n",
+	} {
+		if _, err := ParseNon(strings.NewReader(non)); err == nil {
+			t.Errorf(`unexpected success for %q`, non)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package picross
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	nonogramsOrgDataRe  = regexp.MustCompile(`(?s)var\s+d\s*=\s*(\[\s*\[.*?\]\s*\])\s*;`)
+	nonogramsOrgH1Re    = regexp.MustCompile(`(?is)<h1[^>]*>(.*?)</h1>`)
+	nonogramsOrgTitleRe = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	nonogramsOrgQuoteRe = regexp.MustCompile(`«(.*?)»`)
+	nonogramsOrgTagRe   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// ParseNonogramsOrgPage reads a puzzle page saved from nonograms.org.
+// The solution of the puzzle is embedded in the page as an encoded array of integer arrays
+// (the `var d=[[...],...];` script statement); it is decoded into the goal of the returned puzzle,
+// from which the clues are derived.
+// Only black and white puzzles are supported.
+func ParseNonogramsOrgPage(r io.Reader) (*PicrPuzzle, error) {
+	page, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m := nonogramsOrgDataRe.FindSubmatch(page)
+	if m == nil {
+		return nil, errors.New("NonogramsOrg: encoded puzzle data not found in page")
+	}
+	var data [][]int
+	if err := json.Unmarshal(m[1], &data); err != nil {
+		return nil, fmt.Errorf("NonogramsOrg: malformed puzzle data: %v", err)
+	}
+	goal, err := decodeNonogramsOrg(data)
+	if err != nil {
+		return nil, err
+	}
+	p, err := NewPicrPuzzleFromGoal(goal)
+	if err != nil {
+		return nil, err
+	}
+	p.Title = nonogramsOrgTitle(string(page))
+	return p, nil
+}
+
+// decodeNonogramsOrg deciphers the embedded data of a nonograms.org page into a solution grid.
+//
+// The first rows hold the width, the height and the amount of colors,
+// each obfuscated as a sum of remainders of its elements.
+// Color definitions follow, then the size of the solution,
+// a row of keys, and the solution proper as horizontal runs of a same color,
+// each run given by its starting column, length, color and row, offset by the keys.
+func decodeNonogramsOrg(data [][]int) ([][]CellState, error) {
+	value := func(i int) (int, error) {
+		if i >= len(data) || len(data[i]) < 4 || data[i][3] == 0 {
+			return 0, fmt.Errorf("NonogramsOrg: malformed data row %d", i)
+		}
+		x := data[i]
+		return x[0]%x[3] + x[1]%x[3] - x[2]%x[3], nil
+	}
+	width, err := value(1)
+	if err != nil {
+		return nil, err
+	}
+	height, err := value(2)
+	if err != nil {
+		return nil, err
+	}
+	colors, err := value(3)
+	if err != nil {
+		return nil, err
+	}
+	if width < 1 || height < 1 || width > 1000 || height > 1000 {
+		return nil, fmt.Errorf("NonogramsOrg: unexpected dimensions %dx%d", width, height)
+	}
+	if colors != 1 {
+		return nil, fmt.Errorf("NonogramsOrg: puzzles with %d colors are not supported", colors)
+	}
+	z := colors + 5
+	if z+1 >= len(data) || len(data[z]) < 4 || len(data[z+1]) < 4 || data[z][3] == 0 {
+		return nil, errors.New("NonogramsOrg: missing solution data")
+	}
+	x := data[z]
+	runs := (x[0]%x[3])*(x[0]%x[3]) + (x[1]%x[3])*2 + x[2]%x[3]
+	if runs < 0 || z+2+runs > len(data) {
+		return nil, errors.New("NonogramsOrg: truncated solution data")
+	}
+	keys := data[z+1]
+	goal := make([][]CellState, height)
+	for i := range goal {
+		goal[i] = make([]CellState, width)
+		for j := range goal[i] {
+			goal[i][j] = Gap
+		}
+	}
+	for i := 0; i < runs; i++ {
+		y := data[z+2+i]
+		if len(y) < 4 {
+			return nil, fmt.Errorf("NonogramsOrg: malformed run %d", i+1)
+		}
+		start := y[0] - keys[0] - 1
+		length := y[1] - keys[1]
+		color := y[2] - keys[2]
+		row := y[3] - keys[3] - 1
+		if row < 0 || row >= height || start < 0 || length < 0 || start+length > width || color < 0 || color > colors {
+			return nil, fmt.Errorf("NonogramsOrg: run %d out of bounds", i+1)
+		}
+		if color == 0 {
+			continue
+		}
+		for j := start; j < start+length; j++ {
+			goal[row][j] = Fill
+		}
+	}
+	return goal, nil
+}
+
+// nonogramsOrgTitle extracts the puzzle name from the page heading, or else from the page title,
+// preferring the text quoted by «» (as in `Nonogram «Peacock»`).
+func nonogramsOrgTitle(page string) string {
+	for _, re := range []*regexp.Regexp{nonogramsOrgH1Re, nonogramsOrgTitleRe} {
+		m := re.FindStringSubmatch(page)
+		if m == nil {
+			continue
+		}
+		text := html.UnescapeString(nonogramsOrgTagRe.ReplaceAllString(m[1], ""))
+		if q := nonogramsOrgQuoteRe.FindStringSubmatch(text); q != nil {
+			return strings.TrimSpace(q[1])
+		}
+		if re == nonogramsOrgH1Re && strings.TrimSpace(text) != "" {
+			return strings.TrimSpace(text)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package picross
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// encodeNonogramsOrg obfuscates a black and white grid the way nonograms.org pages embed it.
+func encodeNonogramsOrg(goal [][]CellState) [][]int {
+	value := func(v int) []int { return []int{v + 97*3, 97 * 5, 97 * 2, 97} }
+	keys := []int{3, 5, 7, 11}
+	runs := make([][]int, 0)
+	for r, row := range goal {
+		for c := 0; c < len(row); c++ {
+			if row[c] != Fill {
+				continue
+			}
+			start := c
+			for c < len(row) && row[c] == Fill {
+				c++
+			}
+			runs = append(runs, []int{start + 1 + keys[0], c - start + keys[1], 1 + keys[2], r + 1 + keys[3]})
+		}
+	}
+	data := [][]int{{1, 2, 3, 4}, value(len(goal[0])), value(len(goal)), value(1), {10, 20, 30, 40}, {11, 21, 31, 41}}
+	data = append(data, []int{0, 0, len(runs), 97}, keys)
+	return append(data, runs...)
+}
+
+func nonogramsOrgPage(data [][]int) string {
+	d, _ := json.Marshal(data)
+	return fmt.Sprintf(`<html><head><title>Nonograms &laquo;whatever&raquo;</title></head>
+<body><h1>Nonogram <b>«Small horse»</b></h1>
+<script type="text/javascript">var d=%s;</script></body></html>`, d)
+}
+
+func TestParseNonogramsOrgPage(t *testing.T) {
+	goal := str2Map(`###..
+                     .#..#
+                     .####
+                     .###.
+                     .#.#.`)
+	p, err := ParseNonogramsOrgPage(strings.NewReader(nonogramsOrgPage(encodeNonogramsOrg(goal))))
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if p.Title != `Small horse` {
+		t.Errorf(`unexpected title: %q`, p.Title)
+	}
+	if !areSlices2Equal(p.Goal, goal) {
+		t.Errorf(`goal mismatch: expected %v, got %v`, goal, p.Goal)
+	}
+	s, _ := p.NewSolver(nil)
+	if err := s.solve(); err != nil {
+		t.Errorf(`unexpected solver error: %v`, err)
+	}
+}
+
+// nonogramsOrgHeart is a page excerpt laid out as nonograms.org serves it, with literal data:
+// varying moduli and keys, and the puzzle size and run count obfuscated independently of encodeNonogramsOrg.
+const nonogramsOrgHeart = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Nonogram &laquo;Heart&raquo; &mdash; Nonograms.org</title>
+</head>
+<body>
+<div class="content">
+<h1>Nonogram #0000 &laquo;Heart&raquo;</h1>
+<table class="nonogram_table" id="nonogram_table"></table>
+<script type="text/javascript">
+var d=[[474,696,159,619],[127,514,207,61],[218,491,215,61],[158,491,343,61],[147,670,979,236],[396,529,247,653],
+[143,216,213,71],[92,48,111,176],[94,50,112,177],[97,50,112,177],[93,55,112,178],[93,55,112,179],[94,53,112,180],
+[95,51,112,181],[96,49,112,182]];
+</script>
+</div>
+</body>
+</html>`
+
+func TestParseNonogramsOrgPageExcerpt(t *testing.T) {
+	p, err := ParseNonogramsOrgPage(strings.NewReader(nonogramsOrgHeart))
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if p.Title != `Heart` {
+		t.Errorf(`unexpected title: %q`, p.Title)
+	}
+	rows := [][]uint{{2, 2}, {7}, {7}, {5}, {3}, {1}}
+	cols := [][]uint{{2}, {4}, {5}, {5}, {5}, {4}, {2}}
+	if !areSlices2Equal(p.RowClues, rows) || !areSlices2Equal(p.ColClues, cols) {
+		t.Errorf(`unexpected clues: %v %v`, p.RowClues, p.ColClues)
+	}
+}
+
+func TestParseNonogramsOrgPageFail(t *testing.T) {
+	goal := str2Map(`#.
+                     .#`)
+	colored := encodeNonogramsOrg(goal)
+	colored[3] = []int{2 + 97*3, 97 * 5, 97 * 2, 97}
+	truncated := encodeNonogramsOrg(goal)
+	truncated = truncated[:len(truncated)-1]
+	outside := encodeNonogramsOrg(goal)
+	outside[len(outside)-1][0] += 2
+	for _, page := range []string{
+		`<html><body>no puzzle here</body></html>`,
+		`<script>var d=[[1,2],[x]];</script>`,
+		nonogramsOrgPage(colored),
+		nonogramsOrgPage(truncated),
+		nonogramsOrgPage(outside),
+	} {
+		if _, err := ParseNonogramsOrgPage(bytes.NewBufferString(page)); err == nil {
+			t.Errorf(`unexpected success for %q`, page)
+		}
+	}
+}
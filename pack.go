@@ -0,0 +1,260 @@
+package picross
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// picrPackFormat tags the JSON documents holding a PicrPack.
+const picrPackFormat = "picross-pack"
+
+// picrPackVersion is the current version of the pack format.
+const picrPackVersion = 1
+
+// PicrPackEntry is a puzzle of a pack, together with its metadata.
+// Solution, when present, holds the goal grid as one string per row,
+// with '#' for filled cells and '.' for gaps.
+type PicrPackEntry struct {
+	ID         string   `json:"id,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	Author     string   `json:"author,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty"`
+	Width      uint     `json:"width"`
+	Height     uint     `json:"height"`
+	RowClues   [][]uint `json:"rows"`
+	ColClues   [][]uint `json:"cols"`
+	Solution   []string `json:"solution,omitempty"`
+}
+
+// PicrPack is a collection of puzzles stored as a single JSON document.
+type PicrPack struct {
+	Title   string
+	Entries []*PicrPackEntry
+	path    string
+}
+
+// picrPackDocument is the serialized form of a PicrPack.
+type picrPackDocument struct {
+	Format  string           `json:"format"`
+	Version int              `json:"version"`
+	Title   string           `json:"title,omitempty"`
+	Puzzles []*PicrPackEntry `json:"puzzles"`
+}
+
+// NewPicrPackEntry wraps a puzzle for inclusion in a pack.
+func NewPicrPackEntry(id string, p *PicrPuzzle) (*PicrPackEntry, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	e := &PicrPackEntry{ID: id, Title: p.Title, Width: p.Width(), Height: p.Height(), RowClues: p.RowClues, ColClues: p.ColClues}
+	for _, row := range p.Goal {
+		e.Solution = append(e.Solution, picrFormatRow(row))
+	}
+	return e, nil
+}
+
+// Puzzle returns the puzzle of an entry.
+func (e *PicrPackEntry) Puzzle() (*PicrPuzzle, error) {
+	p := &PicrPuzzle{Title: e.Title, RowClues: e.RowClues, ColClues: e.ColClues}
+	if e.Width != p.Width() || e.Height != p.Height() {
+		return nil, fmt.Errorf("PicrPack: %q: size %dx%d does not match the clues", e.ID, e.Width, e.Height)
+	}
+	if e.Solution != nil {
+		p.Goal = make([][]CellState, len(e.Solution))
+		for i, s := range e.Solution {
+			row, err := picrParseRow(s)
+			if err != nil {
+				return nil, fmt.Errorf("PicrPack: %q: solution row %d: %v", e.ID, i+1, err)
+			}
+			p.Goal[i] = row
+		}
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("PicrPack: %q: %v", e.ID, err)
+	}
+	return p, nil
+}
+
+// HasTag tells whether an entry is labelled by a tag.
+func (e *PicrPackEntry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPicrPack creates an empty pack to be saved at `path`.
+func NewPicrPack(path string, title string) *PicrPack {
+	return &PicrPack{Title: title, path: path}
+}
+
+// OpenPicrPack reads the pack stored at `path`.
+func OpenPicrPack(path string) (*PicrPack, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	pk, err := ReadPicrPack(f)
+	if err != nil {
+		return nil, err
+	}
+	pk.path = path
+	return pk, nil
+}
+
+// ReadPicrPack decodes a pack, validating every puzzle in it.
+func ReadPicrPack(r io.Reader) (*PicrPack, error) {
+	var doc picrPackDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("PicrPack: %v", err)
+	}
+	if doc.Format != picrPackFormat {
+		return nil, errors.New("PicrPack: not a puzzle pack")
+	}
+	if doc.Version > picrPackVersion {
+		return nil, fmt.Errorf("PicrPack: unsupported version %d", doc.Version)
+	}
+	pk := &PicrPack{Title: doc.Title}
+	for _, e := range doc.Puzzles {
+		if err := pk.Append(e); err != nil {
+			return nil, err
+		}
+	}
+	return pk, nil
+}
+
+// Path returns the location of the pack in the file system.
+func (pk *PicrPack) Path() string {
+	return pk.path
+}
+
+// Append adds an entry to the pack, which must have a unique ID and a valid puzzle.
+func (pk *PicrPack) Append(e *PicrPackEntry) error {
+	if e.ID == "" {
+		return errors.New("PicrPack: missing puzzle ID")
+	}
+	if pk.Get(e.ID) != nil {
+		return fmt.Errorf("PicrPack: duplicate puzzle ID %q", e.ID)
+	}
+	if _, err := e.Puzzle(); err != nil {
+		return err
+	}
+	pk.Entries = append(pk.Entries, e)
+	return nil
+}
+
+// Get returns the entry with a given ID, or nil if there is none.
+func (pk *PicrPack) Get(id string) *PicrPackEntry {
+	for _, e := range pk.Entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// Filter returns the entries that satisfy a predicate, in pack order.
+func (pk *PicrPack) Filter(pred func(*PicrPackEntry) bool) []*PicrPackEntry {
+	ans := make([]*PicrPackEntry, 0)
+	for _, e := range pk.Entries {
+		if pred(e) {
+			ans = append(ans, e)
+		}
+	}
+	return ans
+}
+
+// Hash returns the content hash of the puzzle of an entry, as PicrPuzzle.Hash.
+func (e *PicrPackEntry) Hash() string {
+	return (&PicrPuzzle{RowClues: e.RowClues, ColClues: e.ColClues}).Hash()
+}
+
+// Lookup returns the first entry holding a puzzle, or a mirroring, rotation or transposition of it,
+// or nil if there is none.
+func (pk *PicrPack) Lookup(p *PicrPuzzle) *PicrPackEntry {
+	hash := p.Hash()
+	for _, e := range pk.Entries {
+		if e.Hash() == hash {
+			return e
+		}
+	}
+	return nil
+}
+
+// Duplicates returns the groups of entries holding the same puzzle up to mirroring, rotation or transposition,
+// each group in pack order and the groups ordered by their first entry.
+func (pk *PicrPack) Duplicates() [][]*PicrPackEntry {
+	groups := map[string][]*PicrPackEntry{}
+	var hashes []string
+	for _, e := range pk.Entries {
+		hash := e.Hash()
+		if groups[hash] == nil {
+			hashes = append(hashes, hash)
+		}
+		groups[hash] = append(groups[hash], e)
+	}
+	ans := make([][]*PicrPackEntry, 0)
+	for _, hash := range hashes {
+		if len(groups[hash]) > 1 {
+			ans = append(ans, groups[hash])
+		}
+	}
+	return ans
+}
+
+// Write encodes the pack.
+func (pk *PicrPack) Write(w io.Writer) error {
+	doc := picrPackDocument{Format: picrPackFormat, Version: picrPackVersion, Title: pk.Title, Puzzles: pk.Entries}
+	if doc.Puzzles == nil {
+		doc.Puzzles = []*PicrPackEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// Save rewrites the pack at its path.
+// The new content is written to a temporary file which then replaces the old one,
+// so that readers never observe a partially written pack.
+func (pk *PicrPack) Save() error {
+	if pk.path == "" {
+		return errors.New("PicrPack: no path to save to")
+	}
+	f, err := os.CreateTemp(filepath.Dir(pk.path), "."+filepath.Base(pk.path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if err := pk.Write(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if info, err := os.Stat(pk.path); err == nil {
+		os.Chmod(tmp, info.Mode().Perm())
+	} else {
+		os.Chmod(tmp, 0644)
+	}
+	if err := os.Rename(tmp, pk.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
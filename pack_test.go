@@ -0,0 +1,102 @@
+package picross
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPicrPack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `pack.json`)
+	pk := NewPicrPack(path, `Animals`)
+	horse := horsePuzzle()
+	horse.Title = `Horse`
+	horse.Goal = str2Map(`###..
+                          .#..#
+                          .####
+                          .###.
+                          .#.#.`)
+	e, err := NewPicrPackEntry(`horse`, horse)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	e.Tags = []string{`animal`, `small`}
+	if err := pk.Append(e); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	fill := &PicrPuzzle{RowClues: [][]uint{{2}, {2}}, ColClues: [][]uint{{2}, {2}}}
+	e, _ = NewPicrPackEntry(`fill`, fill)
+	e.Difficulty = `Easy`
+	if err := pk.Append(e); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if err := pk.Append(e); err == nil {
+		t.Errorf(`unexpected success appending a duplicate ID`)
+	}
+	if err := pk.Save(); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	pk, err = OpenPicrPack(path)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if pk.Title != `Animals` || len(pk.Entries) != 2 {
+		t.Fatalf(`unexpected pack content: %v %v`, pk.Title, pk.Entries)
+	}
+	animals := pk.Filter(func(e *PicrPackEntry) bool { return e.HasTag(`animal`) })
+	if len(animals) != 1 || animals[0].ID != `horse` {
+		t.Fatalf(`unexpected filter result: %v`, animals)
+	}
+	p, err := animals[0].Puzzle()
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if p.Title != `Horse` || !areSlices2Equal(p.Goal, horse.Goal) || !areSlices2Equal(p.RowClues, horse.RowClues) {
+		t.Errorf(`puzzle mismatch: %v`, p)
+	}
+	if p, _ := pk.Get(`fill`).Puzzle(); p.Goal != nil {
+		t.Errorf(`unexpected goal: %v`, p.Goal)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf(`unexpected leftover files: %v`, entries)
+	}
+}
+
+func TestReadPicrPackFail(t *testing.T) {
+	for _, doc := range []string{
+		`{"format": "something-else", "puzzles": []}`,
+		`{"format": "picross-pack", "version": 99, "puzzles": []}`,
+		`{"format": "picross-pack", "puzzles": [{"id": "a", "width": 2, "height": 1, "rows": [[1]], "cols": [[1]]}]}`,
+		`{"format": "picross-pack", "puzzles": [{"id": "", "width": 1, "height": 1, "rows": [[1]], "cols": [[1]]}]}`,
+		`{"format": "picross-pack", "puzzles": [{"id": "a", "width": 1, "height": 1, "rows": [[1]], "cols": [[1]], "solution": ["x"]}]}`,
+	} {
+		if _, err := ReadPicrPack(strings.NewReader(doc)); err == nil {
+			t.Errorf(`unexpected success for %v`, doc)
+		}
+	}
+}
+
+func TestPicrPackDuplicates(t *testing.T) {
+	pk := NewPicrPack(``, ``)
+	horse, _ := NewPicrPuzzleFromGoal(horseGoal())
+	mirrored, _ := NewPicrPuzzleFromGoal(picrSymmetryGrid(horse.Goal, PicrRotate270))
+	fill := &PicrPuzzle{RowClues: [][]uint{{2}, {2}}, ColClues: [][]uint{{2}, {2}}}
+	for i, p := range []*PicrPuzzle{horse, fill, mirrored, horse} {
+		e, _ := NewPicrPackEntry(string(rune('a'+i)), p)
+		if err := pk.Append(e); err != nil {
+			t.Fatalf(`unexpected error: %v`, err)
+		}
+	}
+	dups := pk.Duplicates()
+	if len(dups) != 1 || len(dups[0]) != 3 || dups[0][0].ID != `a` || dups[0][1].ID != `c` || dups[0][2].ID != `d` {
+		t.Errorf(`unexpected duplicates: %v`, dups)
+	}
+	if e := pk.Lookup(&PicrPuzzle{RowClues: [][]uint{{2}, {2}}, ColClues: [][]uint{{2}, {2}}}); e == nil || e.ID != `b` {
+		t.Errorf(`unexpected entry: %v`, e)
+	}
+	if e := pk.Lookup(&PicrPuzzle{RowClues: [][]uint{{1}}, ColClues: [][]uint{{1}}}); e != nil {
+		t.Errorf(`unexpected entry: %v`, e)
+	}
+}
@@ -0,0 +1,260 @@
+package picross
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+)
+
+// PicrPDFOptions controls the layout of a PDF puzzle book.
+type PicrPDFOptions struct {
+	// Title is printed on the top of every page.
+	Title string
+	// PerPage is the amount of puzzles laid out on each page. Zero selects 1.
+	PerPage uint
+	// AnswerKey appends a section with the solutions of all puzzles.
+	// Puzzles without a goal must have a unique solution, which is searched for.
+	AnswerKey bool
+	// CrossGaps draws Gap cells of the answer key as crosses instead of dots.
+	CrossGaps bool
+}
+
+// A4 page geometry, in points.
+const (
+	picrPdfPageWidth  = 595.0
+	picrPdfPageHeight = 842.0
+	picrPdfMargin     = 36.0
+	picrPdfTitleSize  = 14.0
+	picrPdfLabelSize  = 10.0
+)
+
+// WritePicrPDF writes a printable PDF document with one or more puzzles per page,
+// followed by an optional answer key, using the same layout engine as the image renderers.
+func WritePicrPDF(w io.Writer, puzzles []*PicrPuzzle, opts PicrPDFOptions) error {
+	if len(puzzles) < 1 {
+		return errors.New("PicrPDF: no puzzles")
+	}
+	for i, p := range puzzles {
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("PicrPDF: puzzle %d: %v", i+1, err)
+		}
+	}
+	perPage := int(opts.PerPage)
+	if perPage < 1 {
+		perPage = 1
+	}
+	var solutions [][][]CellState
+	if opts.AnswerKey {
+		solutions = make([][][]CellState, len(puzzles))
+		for i, p := range puzzles {
+			goal, err := p.solution()
+			if err != nil {
+				return fmt.Errorf("PicrPDF: puzzle %d: %v", i+1, err)
+			}
+			solutions[i] = goal
+		}
+	}
+	doc := newPicrPdfDocument()
+	pages := (len(puzzles) + perPage - 1) / perPage
+	totalPages := pages
+	if opts.AnswerKey {
+		totalPages *= 2
+	}
+	for page := 0; page < totalPages; page++ {
+		c := &picrPdfCanvas{}
+		section := opts.Title
+		states := [][][]CellState(nil)
+		first := page * perPage
+		if page >= pages {
+			section = "Solutions"
+			if opts.Title != "" {
+				section = opts.Title + " — " + section
+			}
+			states = solutions
+			first = (page - pages) * perPage
+		}
+		last := first + perPage
+		if last > len(puzzles) {
+			last = len(puzzles)
+		}
+		c.pageText(picrPdfMargin, picrPdfPageHeight-picrPdfMargin-picrPdfTitleSize, picrPdfTitleSize, section)
+		number := fmt.Sprintf("%d", page+1)
+		c.pageText((picrPdfPageWidth-picrPdfTextWidth(number, picrPdfLabelSize))/2, picrPdfMargin/2, picrPdfLabelSize, number)
+		for k := first; k < last; k++ {
+			var state [][]CellState
+			if states != nil {
+				state = states[k]
+			}
+			picrPdfDrawSlot(c, k-first, perPage, k+1, puzzles[k], state, opts.CrossGaps)
+		}
+		doc.addPage(c.buf.Bytes())
+	}
+	return doc.write(w)
+}
+
+// picrPdfDrawSlot draws puzzle number `number` in slot `slot` of a page holding `perPage` puzzles,
+// captioned by its title.
+func picrPdfDrawSlot(c *picrPdfCanvas, slot, perPage, number int, p *PicrPuzzle, state [][]CellState, crossGaps bool) {
+	cols := int(math.Ceil(math.Sqrt(float64(perPage))))
+	rows := (perPage + cols - 1) / cols
+	top := picrPdfMargin + 2*picrPdfTitleSize
+	slotW := (picrPdfPageWidth - 2*picrPdfMargin) / float64(cols)
+	slotH := (picrPdfPageHeight - top - picrPdfMargin) / float64(rows)
+	slotX := picrPdfMargin + float64(slot%cols)*slotW
+	slotY := top + float64(slot/cols)*slotH
+	caption := fmt.Sprintf("%d.", number)
+	if p.Title != "" {
+		caption += " " + p.Title
+	}
+	c.pageText(slotX, picrPdfPageHeight-slotY-picrPdfLabelSize, picrPdfLabelSize, caption)
+	slotY += 2 * picrPdfLabelSize
+	slotH -= 2 * picrPdfLabelSize
+	unit := newPicrLayout(p, 1)
+	cell := math.Min(slotW/unit.width(), slotH/unit.height())
+	l := newPicrLayout(p, cell)
+	c.originX = slotX + (slotW-l.width())/2
+	c.originY = slotY
+	l.draw(c, p, state, nil, crossGaps)
+}
+
+// picrPdfTextWidth approximates the width of a text in Helvetica,
+// exact for digits and close enough for centering short labels.
+func picrPdfTextWidth(s string, size float64) float64 {
+	return 0.556 * size * float64(len([]rune(s)))
+}
+
+// picrPdfCanvas builds the content stream of a PDF page.
+// Layout coordinates are relative to an origin measured from the top left corner of the page.
+type picrPdfCanvas struct {
+	buf     bytes.Buffer
+	originX float64
+	originY float64
+}
+
+func (c *picrPdfCanvas) x(x float64) float64 {
+	return c.originX + x
+}
+
+func (c *picrPdfCanvas) y(y float64) float64 {
+	return picrPdfPageHeight - c.originY - y
+}
+
+func (c *picrPdfCanvas) fillRect(x, y, w, h float64, col color.Color) {
+	if col == picrPaperColor {
+		// The page is already blank.
+		return
+	}
+	fmt.Fprintf(&c.buf, "%s rg %s %s %s %s re f\n", picrPdfColor(col),
+		picrSvgNumber(c.x(x)), picrSvgNumber(c.y(y+h)), picrSvgNumber(w), picrSvgNumber(h))
+}
+
+func (c *picrPdfCanvas) line(x0, y0, x1, y1, width float64, col color.Color) {
+	fmt.Fprintf(&c.buf, "%s RG %s w 2 J %s %s m %s %s l S\n", picrPdfColor(col), picrSvgNumber(width),
+		picrSvgNumber(c.x(x0)), picrSvgNumber(c.y(y0)), picrSvgNumber(c.x(x1)), picrSvgNumber(c.y(y1)))
+}
+
+func (c *picrPdfCanvas) text(x, y, size float64, s string, col color.Color) {
+	// Digits are about 0.7 em tall; center them on the requested point.
+	fmt.Fprintf(&c.buf, "%s rg BT /F1 %s Tf %s %s Td (%s) Tj ET\n", picrPdfColor(col), picrSvgNumber(size),
+		picrSvgNumber(c.x(x)-picrPdfTextWidth(s, size)/2), picrSvgNumber(c.y(y)-0.35*size), picrPdfEscape(s))
+}
+
+// pageText draws left aligned text at absolute page coordinates, with the origin at the bottom left corner.
+func (c *picrPdfCanvas) pageText(x, y, size float64, s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(&c.buf, "0 0 0 rg BT /F1 %s Tf %s %s Td (%s) Tj ET\n",
+		picrSvgNumber(size), picrSvgNumber(x), picrSvgNumber(y), picrPdfEscape(s))
+}
+
+// picrPdfColor formats a color as PDF RGB operands.
+func picrPdfColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("%s %s %s", picrSvgNumber(float64(r)/0xffff), picrSvgNumber(float64(g)/0xffff), picrSvgNumber(float64(b)/0xffff))
+}
+
+// picrPdfEscape encodes a string as the content of a PDF literal string in WinAnsiEncoding,
+// replacing characters that the encoding lacks.
+func picrPdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '—':
+			b.WriteString("\\227")
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// picrPdfDocument accumulates the pages of a PDF file.
+type picrPdfDocument struct {
+	contents [][]byte
+}
+
+func newPicrPdfDocument() *picrPdfDocument {
+	return &picrPdfDocument{}
+}
+
+func (d *picrPdfDocument) addPage(content []byte) {
+	d.contents = append(d.contents, content)
+}
+
+// write serializes the document.
+// Objects are numbered as: 1 catalog, 2 page tree, 3 font, then a page and its content stream for each page.
+func (d *picrPdfDocument) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	var offset int
+	offsets := make([]int, 0)
+	emit := func(format string, args ...interface{}) {
+		n, _ := fmt.Fprintf(bw, format, args...)
+		offset += n
+	}
+	object := func(body string) {
+		offsets = append(offsets, offset)
+		emit("%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	emit("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.contents))
+	for i := range d.contents {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.contents)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	for i, content := range d.contents {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			picrSvgNumber(picrPdfPageWidth), picrSvgNumber(picrPdfPageHeight), 5+2*i))
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write(content)
+		zw.Close()
+		offsets = append(offsets, offset)
+		emit("%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", len(offsets), z.Len())
+		n, _ := bw.Write(z.Bytes())
+		offset += n
+		emit("\nendstream\nendobj\n")
+	}
+	xref := offset
+	emit("xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		emit("%010d 00000 n \n", o)
+	}
+	emit("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return bw.Flush()
+}
@@ -0,0 +1,86 @@
+package picross
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// pdfPageContents inflates the content streams of a document written by WritePicrPDF.
+func pdfPageContents(t *testing.T, doc []byte) []string {
+	ans := make([]string, 0)
+	streams := regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+	for _, m := range streams.FindAllSubmatchIndex(doc, -1) {
+		n, _ := strconv.Atoi(string(doc[m[2]:m[3]]))
+		r, err := zlib.NewReader(bytes.NewReader(doc[m[1] : m[1]+n]))
+		if err != nil {
+			t.Fatalf(`%v`, err)
+		}
+		content, _ := io.ReadAll(r)
+		ans = append(ans, string(content))
+	}
+	return ans
+}
+
+func TestWritePicrPDF(t *testing.T) {
+	horse := horsePuzzle()
+	horse.Title = `Horse (small)`
+	fill := &PicrPuzzle{RowClues: [][]uint{{2}, {2}}, ColClues: [][]uint{{2}, {2}}}
+	var buf bytes.Buffer
+	err := WritePicrPDF(&buf, []*PicrPuzzle{horse, fill, horse}, PicrPDFOptions{Title: `Book`, PerPage: 2, AnswerKey: true})
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	doc := buf.Bytes()
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Errorf(`malformed document envelope`)
+	}
+	if !bytes.Contains(doc, []byte(`/Count 4`)) {
+		t.Errorf(`unexpected page count`)
+	}
+	xref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	if xref == nil {
+		t.Fatalf(`startxref not found`)
+	}
+	if at, _ := strconv.Atoi(string(xref[1])); !bytes.HasPrefix(doc[at:], []byte("xref\n")) {
+		t.Errorf(`startxref does not point to the xref table`)
+	}
+	pages := pdfPageContents(t, doc)
+	if len(pages) != 4 {
+		t.Fatalf(`unexpected amount of content streams: %v`, len(pages))
+	}
+	if !strings.Contains(pages[0], `(1. Horse \(small\)) Tj`) || !strings.Contains(pages[0], `(2.) Tj`) {
+		t.Errorf(`captions not found in first page`)
+	}
+	if !strings.Contains(pages[2], "(Book \\227 Solutions) Tj") {
+		t.Errorf(`answer key title not found`)
+	}
+	if n := strings.Count(pages[0], ` re f`); n != 0 {
+		t.Errorf(`unexpected filled cells in puzzle page: %v`, n)
+	}
+	if n := strings.Count(pages[3], ` re f`); n != 14+11 {
+		t.Errorf(`unexpected filled cells in answer key page: %v`, n)
+	}
+}
+
+func TestWritePicrPDFFail(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePicrPDF(&buf, []*PicrPuzzle{}, PicrPDFOptions{}); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+	ambiguous := &PicrPuzzle{RowClues: [][]uint{{1}, {1}}, ColClues: [][]uint{{1}, {1}}}
+	if err := WritePicrPDF(&buf, []*PicrPuzzle{ambiguous}, PicrPDFOptions{AnswerKey: true}); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+	if err := WritePicrPDF(&buf, []*PicrPuzzle{ambiguous}, PicrPDFOptions{}); err != nil {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+	// A unique solution beyond line logic still makes an answer key.
+	if err := WritePicrPDF(&buf, []*PicrPuzzle{probePuzzle()}, PicrPDFOptions{AnswerKey: true}); err != nil {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+}
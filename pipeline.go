@@ -0,0 +1,237 @@
+package picross
+
+import (
+	"errors"
+	"image"
+	"math"
+)
+
+// PicrPipelineOptions controls how RunPicrPipeline turns an image into a puzzle.
+type PicrPipelineOptions struct {
+	// Crop, when not empty, is the part of the image to use.
+	Crop image.Rectangle
+	// AutoCrop trims the light margins around the darker pixels.
+	AutoCrop bool
+	// Width and Height are the target amount of cells, as in PicrImageOptions.
+	Width  uint
+	Height uint
+	// Scales lists factors applied to the target size, each one giving a variant; nil selects just 1.
+	Scales []float64
+	// Thresholds lists the luminances tried for each size; nil selects 0.3, 0.4, 0.5, 0.6 and 0.7.
+	Thresholds []float64
+	// Dither enables error diffusion while thresholding.
+	Dither bool
+	// Despeckle removes isolated pixels, whose four neighbours all have the other value.
+	Despeckle bool
+	// LineSolvable requires the puzzle to be solvable by line logic alone, rather than just unique.
+	LineSolvable bool
+	// SearchNodes bounds the line logic propagations spent telling whether a variant is unique;
+	// zero selects 2000. Variants that need more are left unsearched.
+	SearchNodes uint
+}
+
+// picrPipelineSearchNodes is the default search budget of a pipeline variant.
+const picrPipelineSearchNodes = 2000
+
+// PicrPipelineVariant is a puzzle derived by the pipeline with a given size and threshold.
+type PicrPipelineVariant struct {
+	Width     uint
+	Height    uint
+	Threshold float64
+	Puzzle    *PicrPuzzle
+	// Despeckled is the amount of isolated pixels removed.
+	Despeckled   uint
+	Unique       bool
+	LineSolvable bool
+	// Searched tells whether Unique was settled by search: when line solvable puzzles are required,
+	// variants that line logic leaves unfinished are not searched, and their uniqueness is unknown.
+	Searched bool
+	// Resemblance, from 0 to 1, tells how close the goal looks to the source image.
+	Resemblance float64
+}
+
+// PicrPipelineResult holds every variant tried by the pipeline and the one chosen, with its rating.
+type PicrPipelineResult struct {
+	Variants []PicrPipelineVariant
+	// Chosen is the index in Variants of the variant that meets the requirements
+	// with the best resemblance, or -1 if none does.
+	Chosen int
+	Rating *PicrRating
+}
+
+// ErrPicrNoVariant reports that no variant tried by the pipeline meets the requirements.
+var ErrPicrNoVariant = errors.New("PicrPipeline: no variant has a unique solution")
+
+// RunPicrPipeline crops, scales, thresholds and despeckles an image,
+// trying every combination of scale and threshold,
+// and chooses the variant with a unique solution (or solvable by line logic, if so requested)
+// that looks closest to the source image.
+// ErrPicrNoVariant is returned, together with the variants tried, when none is acceptable.
+func RunPicrPipeline(img image.Image, opts PicrPipelineOptions) (*PicrPipelineResult, error) {
+	if !opts.Crop.Empty() {
+		sub, ok := img.(interface {
+			SubImage(r image.Rectangle) image.Image
+		})
+		if !ok {
+			return nil, errors.New("PicrPipeline: image cannot be cropped")
+		}
+		img = sub.SubImage(opts.Crop)
+	}
+	lum := picrImageLuminance(img)
+	if opts.AutoCrop {
+		lum = picrAutoCrop(lum)
+	}
+	if len(lum) < 1 || len(lum[0]) < 1 {
+		return nil, errors.New("PicrPipeline: empty image")
+	}
+	scales, thresholds := opts.Scales, opts.Thresholds
+	if scales == nil {
+		scales = []float64{1}
+	}
+	if thresholds == nil {
+		thresholds = []float64{0.3, 0.4, 0.5, 0.6, 0.7}
+	}
+	srcWidth, srcHeight := uint(len(lum[0])), uint(len(lum))
+	width, height := picrImageTargetSize(srcWidth, srcHeight, opts.Width, opts.Height)
+	type size struct{ width, height uint }
+	var sizes []size
+	var refWidth, refHeight uint
+	for _, scale := range scales {
+		w := uint(math.Max(1, math.Round(float64(width)*scale)))
+		h := uint(math.Max(1, math.Round(float64(height)*scale)))
+		sizes = append(sizes, size{w, h})
+		if w*h > refWidth*refHeight {
+			refWidth, refHeight = w, h
+		}
+	}
+	// Variants of every size are compared against the source at the largest size.
+	ref := picrResample(lum, refWidth, refHeight)
+	budget := opts.SearchNodes
+	if budget == 0 {
+		budget = picrPipelineSearchNodes
+	}
+	r := &PicrPipelineResult{Chosen: -1}
+	for _, sz := range sizes {
+		scaled := picrResample(lum, sz.width, sz.height)
+		for _, threshold := range thresholds {
+			goal := picrThreshold(scaled, threshold, opts.Dither)
+			v := PicrPipelineVariant{Width: sz.width, Height: sz.height, Threshold: threshold}
+			if opts.Despeckle {
+				v.Despeckled = picrDespeckle(goal)
+			}
+			p, err := NewPicrPuzzleFromGoal(goal)
+			if err != nil {
+				return nil, err
+			}
+			v.Puzzle = p
+			v.Resemblance = picrResemblance(goal, ref)
+			if _, err := picrPropagate(p, nil); err == nil {
+				v.Unique, v.LineSolvable = true, true
+			} else if !opts.LineSolvable {
+				sr := &PicrSearchResult{}
+				err := p.search(nil, 0, 2, sr, &picrSearchHooks{maxNodes: budget})
+				if err != nil && err != errPicrSearchBudget {
+					return nil, err
+				}
+				v.Searched = err == nil
+				v.Unique = err == nil && len(sr.Solutions) == 1
+			}
+			r.Variants = append(r.Variants, v)
+			acceptable := v.LineSolvable || (v.Unique && !opts.LineSolvable)
+			if acceptable && (r.Chosen < 0 || v.Resemblance > r.Variants[r.Chosen].Resemblance) {
+				r.Chosen = len(r.Variants) - 1
+			}
+		}
+	}
+	if r.Chosen < 0 {
+		return r, ErrPicrNoVariant
+	}
+	rating, err := r.Variants[r.Chosen].Puzzle.Rate()
+	if err != nil {
+		return nil, err
+	}
+	r.Rating = rating
+	return r, nil
+}
+
+// picrAutoCrop trims the rows and columns at the borders of a luminance map that hold no dark pixel.
+// A map without dark pixels is left alone.
+func picrAutoCrop(lum [][]float64) [][]float64 {
+	top, bottom, left, right := len(lum), -1, len(lum[0]), -1
+	for y, row := range lum {
+		for x, v := range row {
+			if v >= 0.5 {
+				continue
+			}
+			if y < top {
+				top = y
+			}
+			if y > bottom {
+				bottom = y
+			}
+			if x < left {
+				left = x
+			}
+			if x > right {
+				right = x
+			}
+		}
+	}
+	if bottom < 0 {
+		return lum
+	}
+	ans := make([][]float64, 0, bottom-top+1)
+	for _, row := range lum[top : bottom+1] {
+		ans = append(ans, row[left:right+1])
+	}
+	return ans
+}
+
+// picrDespeckle flips the cells whose neighbours above, below, left and right all have the other value,
+// returning how many were flipped. Cells at the borders need at least two neighbours.
+func picrDespeckle(grid [][]CellState) uint {
+	var flips [][2]int
+	for i, row := range grid {
+		for j, v := range row {
+			neighbours, others := 0, 0
+			for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				y, x := i+d[0], j+d[1]
+				if y < 0 || y >= len(grid) || x < 0 || x >= len(row) {
+					continue
+				}
+				neighbours += 1
+				if grid[y][x] != v {
+					others += 1
+				}
+			}
+			if neighbours >= 2 && others == neighbours {
+				flips = append(flips, [2]int{i, j})
+			}
+		}
+	}
+	for _, f := range flips {
+		if grid[f[0]][f[1]] == Fill {
+			grid[f[0]][f[1]] = Gap
+		} else {
+			grid[f[0]][f[1]] = Fill
+		}
+	}
+	return uint(len(flips))
+}
+
+// picrResemblance compares a grid with a luminance map, which may have another size,
+// returning 1 when every filled cell covers black pixels and every gap covers white ones.
+func picrResemblance(grid [][]CellState, ref [][]float64) float64 {
+	height, width := len(grid), len(grid[0])
+	var diff float64
+	for y, row := range ref {
+		for x, v := range row {
+			cell := 1.0
+			if grid[y*height/len(ref)][x*width/len(row)] == Fill {
+				cell = 0.0
+			}
+			diff += math.Abs(v - cell)
+		}
+	}
+	return 1 - diff/float64(len(ref)*len(ref[0]))
+}
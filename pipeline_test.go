@@ -0,0 +1,86 @@
+package picross
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// picrTestImage draws a goal with each cell as a block of pixels, surrounded by a white margin.
+func picrTestImage(goal [][]CellState, block, margin int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, len(goal[0])*block+2*margin, len(goal)*block+2*margin))
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			c := color.Gray{255}
+			i, j := (y-margin)/block, (x-margin)/block
+			if y >= margin && x >= margin && i < len(goal) && j < len(goal[0]) && goal[i][j] == Fill {
+				c = color.Gray{0}
+			}
+			img.SetGray(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestRunPicrPipeline(t *testing.T) {
+	horse := str2Map(`###..
+                      .#..#
+                      .####
+                      .###.
+                      .#.#.`)
+	img := picrTestImage(horse, 4, 6)
+	r, err := RunPicrPipeline(img, PicrPipelineOptions{AutoCrop: true, Width: 5, Scales: []float64{1, 2}})
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if len(r.Variants) != 10 || r.Chosen < 0 || r.Rating == nil {
+		t.Fatalf(`unexpected result: %+v`, r)
+	}
+	v := r.Variants[r.Chosen]
+	if !v.LineSolvable || v.Resemblance != 1 {
+		t.Errorf(`unexpected chosen variant: %+v`, v)
+	}
+	if v.Width == 5 && !areSlices2Equal(v.Puzzle.Goal, horse) {
+		t.Errorf(`unexpected goal: %v`, v.Puzzle.Goal)
+	}
+	r, err = RunPicrPipeline(img, PicrPipelineOptions{Crop: image.Rect(6, 6, 26, 26), Height: 5, Thresholds: []float64{0.5}})
+	if err != nil || len(r.Variants) != 1 || !areSlices2Equal(r.Variants[0].Puzzle.Goal, horse) {
+		t.Errorf(`unexpected result of a cropped image: %+v %v`, r, err)
+	}
+}
+
+func TestRunPicrPipelineNoVariant(t *testing.T) {
+	img := picrTestImage(str2Map(`#.
+                                  .#`), 2, 0)
+	r, err := RunPicrPipeline(img, PicrPipelineOptions{})
+	if err != ErrPicrNoVariant {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if r.Chosen != -1 || len(r.Variants) != 5 || r.Variants[0].Unique || !r.Variants[0].Searched {
+		t.Errorf(`unexpected result: %+v`, r)
+	}
+	// Variants whose search exceeds the budget are left unsearched.
+	r, err = RunPicrPipeline(img, PicrPipelineOptions{SearchNodes: 1})
+	if err != ErrPicrNoVariant || r.Variants[0].Searched || r.Variants[0].Unique {
+		t.Errorf(`unexpected result: %+v %v`, r, err)
+	}
+}
+
+func TestPicrDespeckle(t *testing.T) {
+	grid := str2Map(`#....
+                     ..#..
+                     .....
+                     ##..#
+                     ##...`)
+	if n := picrDespeckle(grid); n != 3 {
+		t.Errorf(`unexpected amount of flips: %v`, n)
+	}
+	expected := str2Map(`.....
+                         .....
+                         .....
+                         ##...
+                         ##...`)
+	if !areSlices2Equal(grid, expected) {
+		t.Errorf(`unexpected grid: %v`, grid)
+	}
+}
@@ -0,0 +1,224 @@
+package picross
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+func init() {
+	for _, magic := range []string{"P1", "P2", "P4", "P5"} {
+		image.RegisterFormat("pnm", magic, decodePnm, decodePnmConfig)
+	}
+}
+
+// pnmHeader holds the header fields of a PBM (P1, P4) or PGM (P2, P5) image.
+type pnmHeader struct {
+	magic  string
+	width  int
+	height int
+	maxVal int
+}
+
+// decodePnmConfig reads the header of a PBM or PGM image.
+func decodePnmConfig(r io.Reader) (image.Config, error) {
+	h, err := readPnmHeader(bufio.NewReader(r))
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.GrayModel, Width: h.width, Height: h.height}, nil
+}
+
+// decodePnm decodes a PBM or PGM image, in either plain or raw encoding, to a grayscale image.
+// Marked PBM pixels are black.
+func decodePnm(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	h, err := readPnmHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewGray(image.Rect(0, 0, h.width, h.height))
+	for y := 0; y < h.height; y++ {
+		var bits byte
+		for x := 0; x < h.width; x++ {
+			var v int
+			switch h.magic {
+			case "P1":
+				v, err = readPnmBit(br)
+			case "P2":
+				v, err = readPnmInt(br)
+			case "P4":
+				if x%8 == 0 {
+					bits, err = br.ReadByte()
+				}
+				v = int(bits>>(7-uint(x%8))) & 1
+			case "P5":
+				v, err = readPnmSample(br, h.maxVal)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("pnm: pixel (%d, %d): %v", x, y, err)
+			}
+			if v > h.maxVal {
+				return nil, fmt.Errorf("pnm: pixel (%d, %d) exceeds maximum value %d", x, y, h.maxVal)
+			}
+			if h.magic == "P1" || h.magic == "P4" {
+				v = 1 - v
+			}
+			img.SetGray(x, y, color.Gray{Y: uint8(v * 255 / h.maxVal)})
+		}
+	}
+	return img, nil
+}
+
+// readPnmHeader reads the magic number, the dimensions and (for PGM) the maximum value of a PNM image,
+// leaving the reader positioned at the first pixel.
+func readPnmHeader(br *bufio.Reader) (pnmHeader, error) {
+	var h pnmHeader
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return h, err
+	}
+	h.magic = string(magic)
+	switch h.magic {
+	case "P1", "P2", "P4", "P5":
+	default:
+		return h, errors.New("pnm: unsupported magic number")
+	}
+	var err error
+	if h.width, err = readPnmInt(br); err != nil {
+		return h, fmt.Errorf("pnm: width: %v", err)
+	}
+	if h.height, err = readPnmInt(br); err != nil {
+		return h, fmt.Errorf("pnm: height: %v", err)
+	}
+	if h.width < 1 || h.height < 1 {
+		return h, errors.New("pnm: empty image")
+	}
+	if err := checkPicrImageSize(h.width, h.height); err != nil {
+		return h, fmt.Errorf("pnm: %v", err)
+	}
+	h.maxVal = 1
+	if h.magic == "P2" || h.magic == "P5" {
+		if h.maxVal, err = readPnmInt(br); err != nil {
+			return h, fmt.Errorf("pnm: maximum value: %v", err)
+		}
+		if h.maxVal < 1 || h.maxVal > 65535 {
+			return h, errors.New("pnm: invalid maximum value")
+		}
+	}
+	if h.magic == "P4" || h.magic == "P5" {
+		// A single whitespace separates the header from the raster.
+		if _, err := br.ReadByte(); err != nil {
+			return h, err
+		}
+	}
+	return h, nil
+}
+
+// skipPnmSpace skips whitespace and '#' comments.
+func skipPnmSpace(br *bufio.Reader) error {
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+			continue
+		case '#':
+			if _, err := br.ReadString('\n'); err != nil {
+				return err
+			}
+			continue
+		}
+		return br.UnreadByte()
+	}
+}
+
+// readPnmInt reads a decimal integer of the header or of a plain raster.
+func readPnmInt(br *bufio.Reader) (int, error) {
+	if err := skipPnmSpace(br); err != nil {
+		return 0, err
+	}
+	ans, digits := 0, 0
+	for {
+		c, err := br.ReadByte()
+		if err == io.EOF && digits > 0 {
+			return ans, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if c < '0' || c > '9' {
+			if digits == 0 {
+				return 0, fmt.Errorf("unexpected character %q", c)
+			}
+			return ans, br.UnreadByte()
+		}
+		if ans > 1<<20 {
+			return 0, errors.New("value too large")
+		}
+		ans = 10*ans + int(c-'0')
+		digits += 1
+	}
+}
+
+// readPnmBit reads a single digit of a plain PBM raster, where digits need not be separated.
+func readPnmBit(br *bufio.Reader) (int, error) {
+	if err := skipPnmSpace(br); err != nil {
+		return 0, err
+	}
+	c, err := br.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if c != '0' && c != '1' {
+		return 0, fmt.Errorf("unexpected character %q", c)
+	}
+	return int(c - '0'), nil
+}
+
+// readPnmSample reads a single raw PGM sample, which takes two bytes when the maximum value exceeds 255.
+func readPnmSample(br *bufio.Reader, maxVal int) (int, error) {
+	hi, err := br.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if maxVal < 256 {
+		return int(hi), nil
+	}
+	lo, err := br.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	return int(hi)<<8 | int(lo), nil
+}
+
+// encodePbm writes the goal of a puzzle as a plain PBM image, a pixel per cell.
+func encodePbm(w io.Writer, p *PicrPuzzle) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	if p.Goal == nil {
+		return errors.New("pnm: puzzle has no goal")
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P1\n%d %d\n", p.Width(), p.Height())
+	for _, row := range p.Goal {
+		for j, v := range row {
+			if j > 0 {
+				bw.WriteByte(' ')
+			}
+			if v == Fill {
+				bw.WriteByte('1')
+			} else {
+				bw.WriteByte('0')
+			}
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,51 @@
+package picross
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestDecodePnm(t *testing.T) {
+	inputs := []string{
+		"P1\n# a comment\n3 2\n1 0 1\n011\n",
+		"P2\n3 2\n4\n0 4 0\n4 0 0\n",
+		"P4\n3 2\n\xa0\x60",
+		"P5\n3 2 255\n\x00\xff\x00\xff\x00\x00",
+	}
+	expected := [][]uint8{{0, 255, 0}, {255, 0, 0}}
+	for _, input := range inputs {
+		img, format, err := image.Decode(bytes.NewBufferString(input))
+		if err != nil {
+			t.Errorf(`%q: unexpected error: %v`, input[:2], err)
+			continue
+		}
+		if format != "pnm" {
+			t.Errorf(`%q: unexpected format %v`, input[:2], format)
+		}
+		gray := img.(*image.Gray)
+		for y, row := range expected {
+			for x, v := range row {
+				if got := gray.GrayAt(x, y).Y; got != v {
+					t.Errorf(`%q: pixel (%d, %d): expected %v, got %v`, input[:2], x, y, v, got)
+				}
+			}
+		}
+	}
+}
+
+func TestDecodePnmFail(t *testing.T) {
+	for _, input := range []string{
+		"P1\n0 2\n",
+		"P1\n2 2\n1 0 2 1\n",
+		"P2\n2 1\n3\n1 4\n",
+		"P5\n2 2 255\n\x00",
+		// A hostile header must fail before the pixels are allocated.
+		"P5 9999999 9999999 255",
+		"P4 1000000 1000\n",
+	} {
+		if _, _, err := image.Decode(bytes.NewBufferString(input)); err == nil {
+			t.Errorf(`%q: unexpected success`, input)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package picross
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PicrPuzzle describes a picross puzzle by the clues of its rows and columns.
+// Goal, when not nil, is the expected solution, indexed by row and then by column.
+type PicrPuzzle struct {
+	Title    string
+	RowClues [][]uint
+	ColClues [][]uint
+	Goal     [][]CellState
+}
+
+// NewPicrPuzzle creates a puzzle from its clues,
+// checking that every clue fits in the dimensions implied by the other axis.
+func NewPicrPuzzle(rowClues [][]uint, colClues [][]uint) (*PicrPuzzle, error) {
+	p := &PicrPuzzle{RowClues: rowClues, ColClues: colClues}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewPicrPuzzleFromGoal creates a puzzle whose clues describe a fully determined goal grid.
+// Any cells of the goal are taken as gaps; the puzzle keeps a copy of the goal.
+func NewPicrPuzzleFromGoal(goal [][]CellState) (*PicrPuzzle, error) {
+	if len(goal) < 1 || len(goal[0]) < 1 {
+		return nil, errors.New("PicrPuzzle: empty goal")
+	}
+	for i, row := range goal {
+		if len(row) != len(goal[0]) {
+			return nil, fmt.Errorf("PicrPuzzle: goal row %d has %d cells, expected %d", i+1, len(row), len(goal[0]))
+		}
+	}
+	goal = picrCopyMap(goal)
+	for _, row := range goal {
+		for j, v := range row {
+			if v == Any {
+				row[j] = Gap
+			}
+		}
+	}
+	p := &PicrPuzzle{
+		RowClues: picrMapClues(goal),
+		ColClues: picrMapClues(picrTranspose(goal)),
+		Goal:     goal,
+	}
+	return p, nil
+}
+
+// Width returns the amount of columns of the puzzle.
+func (p *PicrPuzzle) Width() uint {
+	return uint(len(p.ColClues))
+}
+
+// Height returns the amount of rows of the puzzle.
+func (p *PicrPuzzle) Height() uint {
+	return uint(len(p.RowClues))
+}
+
+// NewSolver creates a PicrSolver for the clues of the puzzle.
+func (p *PicrPuzzle) NewSolver(notifCh chan PicrSolverNotification) (*PicrSolver, error) {
+	return NewPicrSolver(p.RowClues, p.ColClues, notifCh)
+}
+
+// validate checks the consistency of the clues and of the goal, if any.
+func (p *PicrPuzzle) validate() error {
+	if len(p.RowClues) < 1 {
+		return errors.New("PicrPuzzle: no rows")
+	}
+	if len(p.ColClues) < 1 {
+		return errors.New("PicrPuzzle: no columns")
+	}
+	for i, clue := range p.RowClues {
+		if picrClueSpan(clue) > p.Width() {
+			return fmt.Errorf("PicrPuzzle: row %d clue %v does not fit in width %d", i+1, clue, p.Width())
+		}
+	}
+	for i, clue := range p.ColClues {
+		if picrClueSpan(clue) > p.Height() {
+			return fmt.Errorf("PicrPuzzle: column %d clue %v does not fit in height %d", i+1, clue, p.Height())
+		}
+	}
+	if p.Goal == nil {
+		return nil
+	}
+	if uint(len(p.Goal)) != p.Height() {
+		return fmt.Errorf("PicrPuzzle: goal has %d rows, expected %d", len(p.Goal), p.Height())
+	}
+	for i, row := range p.Goal {
+		if uint(len(row)) != p.Width() {
+			return fmt.Errorf("PicrPuzzle: goal row %d has %d cells, expected %d", i+1, len(row), p.Width())
+		}
+	}
+	return nil
+}
+
+// picrClueSpan returns the minimum amount of positions needed to honor a clue.
+func picrClueSpan(clue []uint) uint {
+	var ans uint
+	for _, v := range clue {
+		if v == 0 {
+			continue
+		}
+		if ans > 0 {
+			ans += 1
+		}
+		ans += v
+	}
+	return ans
+}
+
+// picrLineClue returns the run lengths of the sequential filled cells of a line.
+func picrLineClue(line []CellState) []uint {
+	ans := make([]uint, 0)
+	var run uint
+	for _, v := range line {
+		if v == Fill {
+			run += 1
+			continue
+		}
+		if run > 0 {
+			ans = append(ans, run)
+			run = 0
+		}
+	}
+	if run > 0 {
+		ans = append(ans, run)
+	}
+	return ans
+}
+
+// GoalMatches tells whether a puzzle has a goal of its size that honors its clues.
+// Zero runs are ignored, so that an empty line may be clued either as {} or as {0}.
+func (p *PicrPuzzle) GoalMatches() bool {
+	return p.Goal != nil && p.validate() == nil && picrGoalMatches(p)
+}
+
+// picrGoalMatches tells whether the goal of a puzzle honors its clues.
+func picrGoalMatches(p *PicrPuzzle) bool {
+	for i, row := range p.Goal {
+		if !picrSameClue(picrLineClue(row), p.RowClues[i]) {
+			return false
+		}
+	}
+	for j, col := range picrTranspose(p.Goal) {
+		if !picrSameClue(picrLineClue(col), p.ColClues[j]) {
+			return false
+		}
+	}
+	return true
+}
+
+// picrMapClues returns the clues of every row of a grid.
+func picrMapClues(mat [][]CellState) [][]uint {
+	ans := make([][]uint, len(mat))
+	for i, row := range mat {
+		ans[i] = picrLineClue(row)
+	}
+	return ans
+}
+
+// picrFormatRow renders a row of cells in the notation of the tests,
+// where '#' is a filled cell, '.' a gap and '?' an unknown cell.
+func picrFormatRow(row []CellState) string {
+	ans := make([]byte, len(row))
+	for i, v := range row {
+		switch v {
+		case Fill:
+			ans[i] = '#'
+		case Gap:
+			ans[i] = '.'
+		default:
+			ans[i] = '?'
+		}
+	}
+	return string(ans)
+}
+
+// picrParseRow reads a row of cells written by picrFormatRow.
+func picrParseRow(s string) ([]CellState, error) {
+	ans := make([]CellState, 0, len(s))
+	for i, c := range s {
+		switch c {
+		case '#':
+			ans = append(ans, Fill)
+		case '.':
+			ans = append(ans, Gap)
+		case '?':
+			ans = append(ans, Any)
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i+1)
+		}
+	}
+	return ans, nil
+}
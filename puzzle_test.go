@@ -0,0 +1,73 @@
+package picross
+
+import (
+	"testing"
+)
+
+func TestNewPicrPuzzle(t *testing.T) {
+	if _, e := NewPicrPuzzle([][]uint{}, [][]uint{{1}}); e == nil {
+		t.Errorf(`unexpected success`)
+	}
+	if _, e := NewPicrPuzzle([][]uint{{1}}, [][]uint{}); e == nil {
+		t.Errorf(`unexpected success`)
+	}
+	if _, e := NewPicrPuzzle([][]uint{{1, 1}}, [][]uint{{1}, {1}}); e == nil {
+		t.Errorf(`unexpected success`)
+	}
+	p, e := NewPicrPuzzle([][]uint{{1, 1}}, [][]uint{{1}, {0}, {1}})
+	if e != nil {
+		t.Fatalf(`unexpected failure: %v`, e)
+	}
+	if p.Width() != 3 || p.Height() != 1 {
+		t.Errorf(`unexpected dimensions: %vx%v`, p.Width(), p.Height())
+	}
+}
+
+func TestPicrClueSpan(t *testing.T) {
+	checks := map[uint][]uint{0: {}, 1: {1}, 5: {2, 2}, 7: {1, 0, 3, 1}}
+	for expected, clue := range checks {
+		if got := picrClueSpan(clue); got != expected {
+			t.Errorf(`clue %v: expected %v, got %v`, clue, expected, got)
+		}
+	}
+}
+
+func TestNewPicrPuzzleFromGoal(t *testing.T) {
+	p, e := NewPicrPuzzleFromGoal(str2Map(`##.#
+                                           ....
+                                           .###`))
+	if e != nil {
+		t.Fatalf(`unexpected failure: %v`, e)
+	}
+	expRows := [][]uint{{2, 1}, {}, {3}}
+	expCols := [][]uint{{1}, {1, 1}, {1}, {1, 1}}
+	if !areSlices2Equal(p.RowClues, expRows) || !areSlices2Equal(p.ColClues, expCols) {
+		t.Errorf(`clues mismatch: expected %v %v, got %v %v`, expRows, expCols, p.RowClues, p.ColClues)
+	}
+	// The goal is a copy, with Any cells taken as gaps.
+	goal := [][]CellState{{Fill, Any}}
+	p, _ = NewPicrPuzzleFromGoal(goal)
+	goal[0][0] = Gap
+	if !areSlices2Equal(p.Goal, [][]CellState{{Fill, Gap}}) {
+		t.Errorf(`unexpected goal: %v`, p.Goal)
+	}
+	if _, e := NewPicrPuzzleFromGoal(str2Map(`##
+                                              #`)); e == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
+
+func TestPicrGoalMatches(t *testing.T) {
+	p := &PicrPuzzle{RowClues: [][]uint{{1}, {0}}, ColClues: [][]uint{{1}, {}}, Goal: str2Map(`#.
+                                                                                             ..`)}
+	if !p.GoalMatches() {
+		t.Errorf(`goal unexpectedly mismatched`)
+	}
+	p.Goal[1][1] = Fill
+	if p.GoalMatches() {
+		t.Errorf(`goal unexpectedly matched`)
+	}
+	if p.Goal = p.Goal[:1]; p.GoalMatches() {
+		t.Errorf(`short goal unexpectedly matched`)
+	}
+}
@@ -0,0 +1,177 @@
+package picross
+
+import (
+	"math"
+	"sort"
+)
+
+// Difficulty tiers of a PicrRating.
+const (
+	PicrTierEasy   = "Easy"
+	PicrTierMedium = "Medium"
+	PicrTierHard   = "Hard"
+	PicrTierExpert = "Expert"
+)
+
+// picrRatingHardest is the amount of hardest line deductions kept by a rating.
+const picrRatingHardest = 5
+
+// PicrRatedLine is a line deduction of the solver:
+// a line that, examined in a given round, yielded some of its unknown cells.
+type PicrRatedLine struct {
+	// Axis is "row" or "col"; Index is 1-based.
+	Axis  string `json:"axis"`
+	Index uint   `json:"index"`
+	Round uint   `json:"round"`
+	Clue  []uint `json:"clue"`
+	// Unknown is the amount of unknown cells of the line before the deduction, and Yield how many it determined.
+	Unknown uint `json:"unknown"`
+	Yield   uint `json:"yield"`
+	// Hardness, from 0 to 1, grows as a deduction yields fewer cells out of more unknown ones of a longer line.
+	Hardness float64 `json:"hardness"`
+}
+
+// PicrRating records the effort it takes to solve a puzzle, combined into a score and a tier.
+type PicrRating struct {
+	// Rounds is the amount of rounds of line logic.
+	Rounds uint `json:"rounds"`
+	// LineCells is the amount of cells determined by line logic alone.
+	LineCells uint `json:"line_cells"`
+	// Deductions is the amount of line deductions that yielded cells, and MeanYield their average yield.
+	Deductions uint    `json:"deductions"`
+	MeanYield  float64 `json:"mean_yield"`
+	// Hardest lists the hardest line deductions, hardest first.
+	Hardest []PicrRatedLine `json:"hardest"`
+	// Probing tells whether line logic had to be helped by probing; see Probe.
+	Probing bool `json:"probing"`
+	Probes  uint `json:"probes"`
+	Forced  uint `json:"forced"`
+	// Search tells whether probing was not enough and cells had to be guessed; see Search.
+	Search     bool    `json:"search"`
+	Guesses    uint    `json:"guesses"`
+	Backtracks uint    `json:"backtracks"`
+	MaxDepth   uint    `json:"max_depth"`
+	Score      float64 `json:"score"`
+	Tier       string  `json:"tier"`
+}
+
+// Rate solves a puzzle with increasingly stronger techniques, recording how much effort each one took.
+// The puzzle must have a unique solution:
+// ErrPicrAmbiguous is returned otherwise, or the error of the solver when the clues contradict each other.
+func (p *PicrPuzzle) Rate() (*PicrRating, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	s, err := p.NewSolver(nil)
+	if err != nil {
+		return nil, err
+	}
+	r := &PicrRating{}
+	var yields uint
+	round := uint(1)
+	lineHook := func(axis string, clues [][]uint) func(uint, []CellState, []CellState, error) {
+		return func(idx uint, before []CellState, after []CellState, err error) {
+			if err != nil {
+				return
+			}
+			var unknown, yield uint
+			for k, v := range before {
+				if v != Any {
+					continue
+				}
+				unknown += 1
+				if after[k] != Any {
+					yield += 1
+				}
+			}
+			if yield == 0 {
+				return
+			}
+			r.Deductions += 1
+			yields += yield
+			r.Hardest = append(r.Hardest, PicrRatedLine{Axis: axis, Index: idx + 1, Round: round, Clue: clues[idx],
+				Unknown: unknown, Yield: yield, Hardness: float64(unknown) / float64(yield) / float64(len(before))})
+		}
+	}
+	s.row.lineHook = lineHook("row", p.RowClues)
+	s.col.lineHook = lineHook("col", p.ColClues)
+	s.roundHook = func() {
+		round += 1
+	}
+	err = s.solve()
+	if isPicrContradiction(err) {
+		return nil, err
+	}
+	r.Rounds = round - 1
+	r.LineCells = p.Width()*p.Height() - picrCountAny(s.getState())
+	if r.Deductions > 0 {
+		r.MeanYield = float64(yields) / float64(r.Deductions)
+	}
+	sort.SliceStable(r.Hardest, func(a, b int) bool { return r.Hardest[a].Hardness > r.Hardest[b].Hardness })
+	if len(r.Hardest) > picrRatingHardest {
+		r.Hardest = r.Hardest[:picrRatingHardest]
+	}
+	if err != nil {
+		if err := r.rateBeyondLines(p); err != nil {
+			return nil, err
+		}
+	}
+	r.Score, r.Tier = r.score()
+	return r, nil
+}
+
+// rateBeyondLines records the effort of probing and, if needed, of searching.
+func (r *PicrRating) rateBeyondLines(p *PicrPuzzle) error {
+	r.Probing = true
+	pr, err := p.Probe()
+	if err != nil {
+		return err
+	}
+	r.Probes, r.Forced = pr.Probes, pr.Forced
+	if picrCountAny(pr.Grid) == 0 {
+		return nil
+	}
+	r.Search = true
+	sr, err := p.Search(2)
+	if err != nil {
+		return err
+	}
+	switch len(sr.Solutions) {
+	case 0:
+		return ErrPicrNoSolution
+	case 1:
+	default:
+		return ErrPicrAmbiguous
+	}
+	r.Guesses, r.Backtracks, r.MaxDepth = sr.Guesses, sr.Backtracks, sr.MaxDepth
+	return nil
+}
+
+// score combines the effort of a rating into a number and a tier.
+// Needing probing makes a puzzle at least Hard, and needing search makes it Expert.
+func (r *PicrRating) score() (float64, string) {
+	score := 1.5 * float64(r.Rounds)
+	if len(r.Hardest) > 0 {
+		var hardness float64
+		for _, l := range r.Hardest {
+			hardness += l.Hardness
+		}
+		score += 10 * hardness / float64(len(r.Hardest))
+	}
+	if r.Probing {
+		score += 15 + 2*float64(r.Forced)
+	}
+	if r.Search {
+		score += 30 + 5*float64(r.Guesses) + 10*float64(r.MaxDepth)
+	}
+	score = math.Round(score*10) / 10
+	switch {
+	case r.Search || score >= 35:
+		return score, PicrTierExpert
+	case r.Probing || score >= 20:
+		return score, PicrTierHard
+	case score >= 12:
+		return score, PicrTierMedium
+	}
+	return score, PicrTierEasy
+}
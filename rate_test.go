@@ -0,0 +1,61 @@
+package picross
+
+import (
+	"testing"
+)
+
+func TestPicrRate(t *testing.T) {
+	r, err := horsePuzzle().Rate()
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if r.Rounds != 3 || r.LineCells != 25 || r.Deductions != 15 || r.Probing || r.Search {
+		t.Errorf(`unexpected rating: %+v`, r)
+	}
+	if len(r.Hardest) != 5 || r.Hardest[0].Hardness != 1 || r.Hardest[0].Axis != `col` || r.Hardest[0].Index != 3 || r.Hardest[0].Yield != 1 {
+		t.Errorf(`unexpected hardest deductions: %+v`, r.Hardest)
+	}
+	if r.Score != 11.7 || r.Tier != PicrTierEasy {
+		t.Errorf(`unexpected score: %v %v`, r.Score, r.Tier)
+	}
+	r, err = probePuzzle().Rate()
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if !r.Probing || r.Forced != 1 || r.Search || r.LineCells == 35 || r.Tier != PicrTierExpert {
+		t.Errorf(`unexpected rating: %+v`, r)
+	}
+}
+
+func TestPicrRateFail(t *testing.T) {
+	p, _ := NewPicrPuzzle([][]uint{{1}, {1}}, [][]uint{{1}, {1}})
+	if _, err := p.Rate(); err != ErrPicrAmbiguous {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+	p, _ = NewPicrPuzzle([][]uint{{2}, {2}}, [][]uint{{1}, {2}})
+	if _, err := p.Rate(); !isPicrContradiction(err) {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+	if _, err := (&PicrPuzzle{}).Rate(); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
+
+func TestPicrRatingScore(t *testing.T) {
+	checks := []struct {
+		r     PicrRating
+		score float64
+		tier  string
+	}{
+		{PicrRating{Rounds: 2}, 3, PicrTierEasy},
+		{PicrRating{Rounds: 6, Hardest: []PicrRatedLine{{Hardness: 0.5}, {Hardness: 0.7}}}, 15, PicrTierMedium},
+		{PicrRating{Rounds: 14}, 21, PicrTierHard},
+		{PicrRating{Rounds: 1, Probing: true}, 16.5, PicrTierHard},
+		{PicrRating{Rounds: 1, Probing: true, Search: true, Guesses: 2, MaxDepth: 1}, 66.5, PicrTierExpert},
+	}
+	for _, c := range checks {
+		if score, tier := c.r.score(); score != c.score || tier != c.tier {
+			t.Errorf(`%+v: expected %v %v, got %v %v`, c.r, c.score, c.tier, score, tier)
+		}
+	}
+}
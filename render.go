@@ -0,0 +1,369 @@
+package picross
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+)
+
+// PicrRenderOptions controls the rendering of a puzzle.
+type PicrRenderOptions struct {
+	// CellSize is the side of a cell, in pixels (or points, for vector outputs).
+	// Zero selects 20.
+	CellSize float64
+	// State is the grid to draw, indexed by row and then by column.
+	// Any cells are left blank. A nil State renders the empty puzzle;
+	// pass the Goal of the puzzle to render its solution.
+	State [][]CellState
+	// CrossGaps draws Gap cells as crosses instead of dots.
+	CrossGaps bool
+	// Highlight, when not nil, tints the background of the cells with a nonzero value,
+	// one color per value, as the regions of a PicrAmbiguity.
+	Highlight [][]uint
+}
+
+var (
+	picrInkColor   = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	picrPaperColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	picrGapColor   = color.RGBA{0x80, 0x80, 0x80, 0xff}
+	// picrHighlightColors tint highlighted cells, cycling by value.
+	picrHighlightColors = []color.Color{
+		color.RGBA{0xff, 0xb3, 0xb3, 0xff},
+		color.RGBA{0xb3, 0xd9, 0xff, 0xff},
+		color.RGBA{0xb3, 0xff, 0xb3, 0xff},
+		color.RGBA{0xff, 0xe0, 0x99, 0xff},
+		color.RGBA{0xe0, 0xb3, 0xff, 0xff},
+		color.RGBA{0xb3, 0xff, 0xf0, 0xff},
+	}
+)
+
+// picrCanvas is a drawing surface for the layout engine.
+// Coordinates grow rightwards and downwards from the top left corner.
+type picrCanvas interface {
+	fillRect(x, y, w, h float64, c color.Color)
+	line(x0, y0, x1, y1, width float64, c color.Color)
+	// text draws `s` centered on (x, y), `size` units tall.
+	text(x, y, size float64, s string, c color.Color)
+}
+
+// picrLayout places the clues and the grid of a puzzle.
+// Row clues are laid out to the left of the grid and column clues on top of it,
+// each number of a clue taking a cell sized slot.
+type picrLayout struct {
+	cell     float64
+	pad      float64
+	rowSlots int
+	colSlots int
+	rows     int
+	cols     int
+}
+
+// newPicrLayout sizes the clue margins after the longest clue of each axis.
+func newPicrLayout(p *PicrPuzzle, cell float64) picrLayout {
+	if cell <= 0 {
+		cell = 20
+	}
+	l := picrLayout{cell: cell, pad: cell / 2, rows: len(p.RowClues), cols: len(p.ColClues), rowSlots: 1, colSlots: 1}
+	for _, clue := range p.RowClues {
+		if n := len(picrClueNumbers(clue)); n > l.rowSlots {
+			l.rowSlots = n
+		}
+	}
+	for _, clue := range p.ColClues {
+		if n := len(picrClueNumbers(clue)); n > l.colSlots {
+			l.colSlots = n
+		}
+	}
+	return l
+}
+
+// gridX returns the horizontal position of the left edge of column `col` of the grid.
+func (l picrLayout) gridX(col int) float64 {
+	return l.pad + float64(l.rowSlots+col)*l.cell
+}
+
+// gridY returns the vertical position of the top edge of row `row` of the grid.
+func (l picrLayout) gridY(row int) float64 {
+	return l.pad + float64(l.colSlots+row)*l.cell
+}
+
+// width returns the total width of the drawing.
+func (l picrLayout) width() float64 {
+	return l.gridX(l.cols) + l.pad
+}
+
+// height returns the total height of the drawing.
+func (l picrLayout) height() float64 {
+	return l.gridY(l.rows) + l.pad
+}
+
+// textSize returns a text height that fits `s` in a single slot.
+func (l picrLayout) textSize(s string) float64 {
+	size := 0.6 * l.cell
+	glyphWidth := float64(picrTextWidth(s))
+	if fit := 0.9 * l.cell * picrGlyphHeight / glyphWidth; fit < size {
+		size = fit
+	}
+	return size
+}
+
+// draw renders the puzzle clues, the grid, the highlighted cells and the cells of `state`
+// (both of which may be nil) onto a canvas.
+func (l picrLayout) draw(c picrCanvas, p *PicrPuzzle, state [][]CellState, highlight [][]uint, crossGaps bool) {
+	c.fillRect(0, 0, l.width(), l.height(), picrPaperColor)
+	for i, clue := range p.RowClues {
+		numbers := picrClueNumbers(clue)
+		for k, s := range numbers {
+			x := l.gridX(k-len(numbers)) + l.cell/2
+			c.text(x, l.gridY(i)+l.cell/2, l.textSize(s), s, picrInkColor)
+		}
+	}
+	for i, clue := range p.ColClues {
+		numbers := picrClueNumbers(clue)
+		for k, s := range numbers {
+			y := l.gridY(k-len(numbers)) + l.cell/2
+			c.text(l.gridX(i)+l.cell/2, y, l.textSize(s), s, picrInkColor)
+		}
+	}
+	for i, row := range highlight {
+		for j, v := range row {
+			if v > 0 {
+				c.fillRect(l.gridX(j), l.gridY(i), l.cell, l.cell, picrHighlightColors[(v-1)%uint(len(picrHighlightColors))])
+			}
+		}
+	}
+	for i, row := range state {
+		for j, v := range row {
+			x, y := l.gridX(j), l.gridY(i)
+			switch v {
+			case Fill:
+				c.fillRect(x, y, l.cell, l.cell, picrInkColor)
+			case Gap:
+				if crossGaps {
+					m := l.cell / 4
+					c.line(x+m, y+m, x+l.cell-m, y+l.cell-m, l.cell/12, picrGapColor)
+					c.line(x+l.cell-m, y+m, x+m, y+l.cell-m, l.cell/12, picrGapColor)
+				} else {
+					d := l.cell / 6
+					c.fillRect(x+(l.cell-d)/2, y+(l.cell-d)/2, d, d, picrGapColor)
+				}
+			}
+		}
+	}
+	thin := math.Max(1, l.cell/20)
+	for i := 0; i <= l.rows; i++ {
+		width := thin
+		if i%5 == 0 || i == l.rows {
+			width = 2 * thin
+		}
+		c.line(l.gridX(0), l.gridY(i), l.gridX(l.cols), l.gridY(i), width, picrInkColor)
+	}
+	for j := 0; j <= l.cols; j++ {
+		width := thin
+		if j%5 == 0 || j == l.cols {
+			width = 2 * thin
+		}
+		c.line(l.gridX(j), l.gridY(0), l.gridX(j), l.gridY(l.rows), width, picrInkColor)
+	}
+}
+
+// picrClueNumbers returns the textual numbers of a clue, where an empty clue reads as a single zero.
+func picrClueNumbers(clue []uint) []string {
+	ans := make([]string, 0, len(clue))
+	for _, v := range clue {
+		if v == 0 {
+			continue
+		}
+		ans = append(ans, strconv.FormatUint(uint64(v), 10))
+	}
+	if len(ans) == 0 {
+		ans = append(ans, "0")
+	}
+	return ans
+}
+
+// checkPicrRenderState verifies that the state to render matches the dimensions of the puzzle.
+func checkPicrRenderState(p *PicrPuzzle, state [][]CellState) error {
+	if state == nil {
+		return nil
+	}
+	if uint(len(state)) != p.Height() {
+		return fmt.Errorf("PicrRender: state has %d rows, expected %d", len(state), p.Height())
+	}
+	for i, row := range state {
+		if uint(len(row)) != p.Width() {
+			return fmt.Errorf("PicrRender: state row %d has %d cells, expected %d", i+1, len(row), p.Width())
+		}
+	}
+	return nil
+}
+
+// checkPicrRenderHighlight verifies that the highlighted cells match the dimensions of the puzzle.
+func checkPicrRenderHighlight(p *PicrPuzzle, highlight [][]uint) error {
+	if highlight == nil {
+		return nil
+	}
+	if uint(len(highlight)) != p.Height() {
+		return fmt.Errorf("PicrRender: highlight has %d rows, expected %d", len(highlight), p.Height())
+	}
+	for i, row := range highlight {
+		if uint(len(row)) != p.Width() {
+			return fmt.Errorf("PicrRender: highlight row %d has %d cells, expected %d", i+1, len(row), p.Width())
+		}
+	}
+	return nil
+}
+
+// RenderPicrSVG writes an SVG picture of a puzzle.
+func RenderPicrSVG(w io.Writer, p *PicrPuzzle, opts PicrRenderOptions) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	if err := checkPicrRenderState(p, opts.State); err != nil {
+		return err
+	}
+	if err := checkPicrRenderHighlight(p, opts.Highlight); err != nil {
+		return err
+	}
+	l := newPicrLayout(p, opts.CellSize)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		picrSvgNumber(l.width()), picrSvgNumber(l.height()), picrSvgNumber(l.width()), picrSvgNumber(l.height()))
+	if p.Title != "" {
+		fmt.Fprintf(bw, "<title>%s</title>\n", picrXMLEscape(p.Title))
+	}
+	l.draw(&picrSvgCanvas{w: bw}, p, opts.State, opts.Highlight, opts.CrossGaps)
+	fmt.Fprintf(bw, "</svg>\n")
+	return bw.Flush()
+}
+
+// RenderPicrImage draws a puzzle onto a new raster image.
+func RenderPicrImage(p *PicrPuzzle, opts PicrRenderOptions) (*image.RGBA, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	if err := checkPicrRenderState(p, opts.State); err != nil {
+		return nil, err
+	}
+	if err := checkPicrRenderHighlight(p, opts.Highlight); err != nil {
+		return nil, err
+	}
+	l := newPicrLayout(p, opts.CellSize)
+	img := image.NewRGBA(image.Rect(0, 0, int(math.Ceil(l.width())), int(math.Ceil(l.height()))))
+	l.draw(&picrRasterCanvas{img: img}, p, opts.State, opts.Highlight, opts.CrossGaps)
+	return img, nil
+}
+
+// RenderPicrPNG writes a PNG picture of a puzzle.
+func RenderPicrPNG(w io.Writer, p *PicrPuzzle, opts PicrRenderOptions) error {
+	img, err := RenderPicrImage(p, opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// picrSvgCanvas emits SVG elements.
+type picrSvgCanvas struct {
+	w io.Writer
+}
+
+func (c *picrSvgCanvas) fillRect(x, y, w, h float64, col color.Color) {
+	fmt.Fprintf(c.w, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n",
+		picrSvgNumber(x), picrSvgNumber(y), picrSvgNumber(w), picrSvgNumber(h), picrSvgColor(col))
+}
+
+func (c *picrSvgCanvas) line(x0, y0, x1, y1, width float64, col color.Color) {
+	fmt.Fprintf(c.w, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" stroke-width="%s" stroke-linecap="square"/>`+"\n",
+		picrSvgNumber(x0), picrSvgNumber(y0), picrSvgNumber(x1), picrSvgNumber(y1), picrSvgColor(col), picrSvgNumber(width))
+}
+
+func (c *picrSvgCanvas) text(x, y, size float64, s string, col color.Color) {
+	fmt.Fprintf(c.w, `<text x="%s" y="%s" font-family="sans-serif" font-size="%s" text-anchor="middle" dominant-baseline="central" fill="%s">%s</text>`+"\n",
+		picrSvgNumber(x), picrSvgNumber(y), picrSvgNumber(size), picrSvgColor(col), picrXMLEscape(s))
+}
+
+// picrSvgNumber formats a coordinate compactly.
+func picrSvgNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// picrSvgColor formats a color as a hex triplet.
+func picrSvgColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// picrXMLEscape escapes text for XML content and attributes.
+func picrXMLEscape(s string) string {
+	ans := make([]rune, 0, len(s))
+	for _, r := range s {
+		switch r {
+		case '&':
+			ans = append(ans, []rune("&amp;")...)
+		case '<':
+			ans = append(ans, []rune("&lt;")...)
+		case '>':
+			ans = append(ans, []rune("&gt;")...)
+		case '"':
+			ans = append(ans, []rune("&quot;")...)
+		default:
+			ans = append(ans, r)
+		}
+	}
+	return string(ans)
+}
+
+// picrRasterCanvas paints onto a raster image, rendering text with picrGlyphs.
+type picrRasterCanvas struct {
+	img draw.Image
+}
+
+func (c *picrRasterCanvas) fillRect(x, y, w, h float64, col color.Color) {
+	x0, y0 := int(math.Round(x)), int(math.Round(y))
+	x1, y1 := int(math.Round(x+w)), int(math.Round(y+h))
+	if x1 <= x0 {
+		x1 = x0 + 1
+	}
+	if y1 <= y0 {
+		y1 = y0 + 1
+	}
+	draw.Draw(c.img, image.Rect(x0, y0, x1, y1), image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+func (c *picrRasterCanvas) line(x0, y0, x1, y1, width float64, col color.Color) {
+	if x0 == x1 || y0 == y1 {
+		c.fillRect(math.Min(x0, x1)-width/2, math.Min(y0, y1)-width/2, math.Abs(x1-x0)+width, math.Abs(y1-y0)+width, col)
+		return
+	}
+	steps := int(math.Ceil(2 * math.Max(math.Abs(x1-x0), math.Abs(y1-y0))))
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		c.fillRect(x0+t*(x1-x0)-width/2, y0+t*(y1-y0)-width/2, width, width, col)
+	}
+}
+
+func (c *picrRasterCanvas) text(x, y, size float64, s string, col color.Color) {
+	scale := math.Max(1, math.Floor(size/picrGlyphHeight))
+	left := x - scale*float64(picrTextWidth(s))/2
+	top := y - scale*picrGlyphHeight/2
+	for _, r := range s {
+		glyph := picrGlyph(r)
+		for gy, row := range glyph {
+			for gx, px := range row {
+				if px != '#' {
+					continue
+				}
+				c.fillRect(left+scale*float64(gx), top+scale*float64(gy), scale, scale, col)
+			}
+		}
+		left += scale * float64(len(glyph[0])+1)
+	}
+}
@@ -0,0 +1,124 @@
+package picross
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func horsePuzzle() *PicrPuzzle {
+	p, _ := NewPicrPuzzle(
+		[][]uint{{3}, {1, 1}, {4}, {3}, {1, 1}},
+		[][]uint{{1}, {5}, {1, 2}, {3}, {2}})
+	return p
+}
+
+func TestNewPicrLayout(t *testing.T) {
+	l := newPicrLayout(horsePuzzle(), 10)
+	if l.rowSlots != 2 || l.colSlots != 2 {
+		t.Errorf(`unexpected clue slots: %v %v`, l.rowSlots, l.colSlots)
+	}
+	if l.width() != 80 || l.height() != 80 {
+		t.Errorf(`unexpected size: %v %v`, l.width(), l.height())
+	}
+	if l.gridX(0) != 25 || l.gridY(5) != 75 {
+		t.Errorf(`unexpected grid position: %v %v`, l.gridX(0), l.gridY(5))
+	}
+}
+
+func TestRenderPicrSVG(t *testing.T) {
+	p := horsePuzzle()
+	p.Title = `horse & rider`
+	var buf bytes.Buffer
+	if err := RenderPicrSVG(&buf, p, PicrRenderOptions{}); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	svg := buf.String()
+	if !strings.Contains(svg, `<title>horse &amp; rider</title>`) {
+		t.Errorf(`title not found`)
+	}
+	if n := strings.Count(svg, `<text `); n != 7+6 {
+		t.Errorf(`unexpected amount of clue numbers: %v`, n)
+	}
+	if n := strings.Count(svg, `<rect `); n != 1 {
+		t.Errorf(`unexpected amount of rectangles: %v`, n)
+	}
+	p.Goal = str2Map(`###..
+                      .#..#
+                      .####
+                      .###.
+                      .#.#.`)
+	buf.Reset()
+	if err := RenderPicrSVG(&buf, p, PicrRenderOptions{State: p.Goal, CrossGaps: true}); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	svg = buf.String()
+	if n := strings.Count(svg, `<rect `); n != 1+14 {
+		t.Errorf(`unexpected amount of rectangles: %v`, n)
+	}
+	if n := strings.Count(svg, `<line `); n != 12+2*11 {
+		t.Errorf(`unexpected amount of lines: %v`, n)
+	}
+	if err := RenderPicrSVG(&buf, p, PicrRenderOptions{State: p.Goal[:2]}); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
+
+func TestRenderPicrPNG(t *testing.T) {
+	p := horsePuzzle()
+	state := str2Map(`###..
+                      .#..#
+                      .####
+                      .###.
+                      .#.#.`)
+	state[0][0] = Any
+	var buf bytes.Buffer
+	if err := RenderPicrPNG(&buf, p, PicrRenderOptions{CellSize: 10, State: state}); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if b := img.Bounds(); b.Dx() != 80 || b.Dy() != 80 {
+		t.Fatalf(`unexpected size: %v`, b)
+	}
+	checkPixel := func(x, y int, expected uint32) {
+		if r, _, _, _ := img.At(x, y).RGBA(); r>>8 != expected {
+			t.Errorf(`pixel (%d, %d): expected %v, got %v`, x, y, expected, r>>8)
+		}
+	}
+	// Any cell.
+	checkPixel(30, 30, 0xff)
+	// Fill cell.
+	checkPixel(40, 30, 0x00)
+	// Gap cell dot.
+	checkPixel(30, 40, 0x80)
+}
+
+func TestRenderPicrHighlight(t *testing.T) {
+	p := horsePuzzle()
+	highlight := make([][]uint, 5)
+	for i := range highlight {
+		highlight[i] = make([]uint, 5)
+	}
+	highlight[0][0], highlight[0][1] = 1, 2
+	img, err := RenderPicrImage(p, PicrRenderOptions{CellSize: 10, Highlight: highlight})
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	for _, c := range []struct {
+		x, y     int
+		expected [3]uint32
+	}{{28, 28, [3]uint32{0xff, 0xb3, 0xb3}}, {38, 28, [3]uint32{0xb3, 0xd9, 0xff}}, {48, 28, [3]uint32{0xff, 0xff, 0xff}}} {
+		r, g, b, _ := img.At(c.x, c.y).RGBA()
+		if [3]uint32{r >> 8, g >> 8, b >> 8} != c.expected {
+			t.Errorf(`pixel (%d, %d): expected %v, got %v %v %v`, c.x, c.y, c.expected, r>>8, g>>8, b>>8)
+		}
+	}
+	var buf bytes.Buffer
+	if err := RenderPicrSVG(&buf, p, PicrRenderOptions{Highlight: highlight[:2]}); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
@@ -0,0 +1,198 @@
+package picross
+
+import (
+	"errors"
+)
+
+// PicrCell is a cell of a grid with a value; Row and Col are 1-based.
+type PicrCell struct {
+	Row   uint      `json:"row"`
+	Col   uint      `json:"col"`
+	Value CellState `json:"value"`
+}
+
+// PicrRepairOptions controls the fixes proposed for an ambiguous design.
+type PicrRepairOptions struct {
+	// LineSolvable requires the fixed puzzle to be solved by line logic alone;
+	// otherwise a unique solution is enough.
+	LineSolvable bool
+	// MaxCells is the largest amount of givens or flips proposed. Zero selects 10.
+	MaxCells uint
+}
+
+// ErrPicrNoGoal reports a puzzle lacking the goal grid that an operation needs.
+var ErrPicrNoGoal = errors.New("PicrPuzzle: no goal grid")
+
+// ErrPicrNoRepair reports that no fix was found within the allowed amount of cells.
+var ErrPicrNoRepair = errors.New("PicrRepair: no fix found")
+
+// picrRepairTarget tells whether a puzzle, with some cells given, meets the requirement of a repair.
+// It also returns the amount of cells that line logic leaves unknown, which measures how far it is.
+func picrRepairTarget(p *PicrPuzzle, seed [][]CellState, lineSolvable bool) (bool, uint, error) {
+	grid, err := picrPropagate(p, seed)
+	if err == nil {
+		return true, 0, nil
+	}
+	if isPicrContradiction(err) {
+		return false, 0, err
+	}
+	unknown := picrCountAny(grid)
+	if lineSolvable {
+		return false, unknown, nil
+	}
+	r := &PicrSearchResult{}
+	if err := p.search(seed, 0, 2, r, nil); err != nil {
+		return false, unknown, err
+	}
+	return len(r.Solutions) == 1, unknown, nil
+}
+
+// picrRepairOpts validates a puzzle for repair and fills in the defaults of the options.
+func picrRepairOpts(p *PicrPuzzle, opts PicrRepairOptions) (PicrRepairOptions, error) {
+	if err := p.validate(); err != nil {
+		return opts, err
+	}
+	if p.Goal == nil {
+		return opts, ErrPicrNoGoal
+	}
+	if !picrGoalMatches(p) {
+		return opts, errors.New("PicrRepair: goal does not match the clues")
+	}
+	if opts.MaxCells == 0 {
+		opts.MaxCells = 10
+	}
+	return opts, nil
+}
+
+// SuggestGivens proposes cells of the goal to reveal at the start,
+// so that the puzzle gets a unique solution, or becomes solvable by line logic if so requested.
+// Cells are chosen greedily by how much line logic they unlock, and then pruned while the requirement holds.
+// No cells are returned when the puzzle already meets the requirement.
+func (p *PicrPuzzle) SuggestGivens(opts PicrRepairOptions) ([]PicrCell, error) {
+	opts, err := picrRepairOpts(p, opts)
+	if err != nil {
+		return nil, err
+	}
+	seed := make([][]CellState, p.Height())
+	for i := range seed {
+		seed[i] = make([]CellState, p.Width())
+	}
+	var givens []PicrCell
+	for {
+		ok, _, err := picrRepairTarget(p, seed, opts.LineSolvable)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			break
+		}
+		if uint(len(givens)) >= opts.MaxCells {
+			return nil, ErrPicrNoRepair
+		}
+		grid, _ := picrPropagate(p, seed)
+		var best PicrCell
+		bestUnknown := ^uint(0)
+		for i, row := range grid {
+			for j, v := range row {
+				if v != Any {
+					continue
+				}
+				seed[i][j] = p.Goal[i][j]
+				after, err := picrPropagate(p, seed)
+				seed[i][j] = Any
+				if isPicrContradiction(err) {
+					continue
+				}
+				if n := picrCountAny(after); n < bestUnknown {
+					best, bestUnknown = PicrCell{Row: uint(i) + 1, Col: uint(j) + 1, Value: p.Goal[i][j]}, n
+				}
+			}
+		}
+		if bestUnknown == ^uint(0) {
+			return nil, ErrPicrNoRepair
+		}
+		givens = append(givens, best)
+		seed[best.Row-1][best.Col-1] = best.Value
+	}
+	// Earlier choices may have been made redundant by later ones.
+	for k := 0; k < len(givens); {
+		c := givens[k]
+		seed[c.Row-1][c.Col-1] = Any
+		if ok, _, err := picrRepairTarget(p, seed, opts.LineSolvable); err == nil && ok {
+			givens = append(givens[:k], givens[k+1:]...)
+			continue
+		}
+		seed[c.Row-1][c.Col-1] = c.Value
+		k += 1
+	}
+	return givens, nil
+}
+
+// SuggestFlips proposes cells of the goal to toggle, keeping the picture close to the original,
+// so that the puzzle gets a unique solution, or becomes solvable by line logic if so requested.
+// It returns the flipped cells, with their new values, and the puzzle of the flipped goal.
+// Single flips are tried first; failing that, flips are chosen greedily by how much line logic they unlock.
+// No cells are returned when the puzzle already meets the requirement.
+func (p *PicrPuzzle) SuggestFlips(opts PicrRepairOptions) ([]PicrCell, *PicrPuzzle, error) {
+	opts, err := picrRepairOpts(p, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	cur := p
+	var flips []PicrCell
+	flipped := map[[2]int]bool{}
+	for {
+		ok, _, err := picrRepairTarget(cur, nil, opts.LineSolvable)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			break
+		}
+		if uint(len(flips)) >= opts.MaxCells {
+			return nil, nil, ErrPicrNoRepair
+		}
+		grid, _ := picrPropagate(cur, nil)
+		var best PicrCell
+		var bestPuzzle *PicrPuzzle
+		bestUnknown := ^uint(0)
+	picrSuggestFlipsCandidates:
+		for i, row := range grid {
+			for j, v := range row {
+				if v != Any || flipped[[2]int{i, j}] {
+					continue
+				}
+				goal := picrCopyMap(cur.Goal)
+				goal[i][j] = Fill
+				if cur.Goal[i][j] == Fill {
+					goal[i][j] = Gap
+				}
+				q, err := NewPicrPuzzleFromGoal(goal)
+				if err != nil {
+					return nil, nil, err
+				}
+				q.Title = p.Title
+				ok, unknown, err := picrRepairTarget(q, nil, opts.LineSolvable)
+				if err != nil {
+					continue
+				}
+				if ok {
+					unknown = 0
+				}
+				if unknown < bestUnknown || ok {
+					best, bestPuzzle, bestUnknown = PicrCell{Row: uint(i) + 1, Col: uint(j) + 1, Value: goal[i][j]}, q, unknown
+				}
+				if ok {
+					break picrSuggestFlipsCandidates
+				}
+			}
+		}
+		if bestPuzzle == nil {
+			return nil, nil, ErrPicrNoRepair
+		}
+		flips = append(flips, best)
+		flipped[[2]int{int(best.Row) - 1, int(best.Col) - 1}] = true
+		cur = bestPuzzle
+	}
+	return flips, cur, nil
+}
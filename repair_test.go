@@ -0,0 +1,92 @@
+package picross
+
+import (
+	"testing"
+)
+
+// probeGoalPuzzle is probePuzzle together with its goal.
+func probeGoalPuzzle() *PicrPuzzle {
+	p, _ := NewPicrPuzzleFromGoal(str2Map(`####.
+                                           ..#..
+                                           ...#.
+                                           .#..#
+                                           ....#
+                                           ##.#.
+                                           ##...`))
+	return p
+}
+
+func TestPicrSuggestGivens(t *testing.T) {
+	p, _ := NewPicrPuzzleFromGoal(str2Map(`#.
+                                           .#`))
+	givens, err := p.SuggestGivens(PicrRepairOptions{})
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if len(givens) != 1 || givens[0] != (PicrCell{Row: 1, Col: 1, Value: Fill}) {
+		t.Errorf(`unexpected givens: %v`, givens)
+	}
+	p = probeGoalPuzzle()
+	if givens, err = p.SuggestGivens(PicrRepairOptions{}); err != nil || len(givens) != 0 {
+		t.Errorf(`unexpected givens of a unique puzzle: %v %v`, givens, err)
+	}
+	givens, err = p.SuggestGivens(PicrRepairOptions{LineSolvable: true})
+	if err != nil || len(givens) != 1 {
+		t.Fatalf(`unexpected givens: %v %v`, givens, err)
+	}
+	seed := make([][]CellState, 7)
+	for i := range seed {
+		seed[i] = make([]CellState, 5)
+	}
+	seed[givens[0].Row-1][givens[0].Col-1] = givens[0].Value
+	if _, err := picrPropagate(p, seed); err != nil {
+		t.Errorf(`givens do not make the puzzle line solvable: %v`, err)
+	}
+}
+
+func TestPicrSuggestFlips(t *testing.T) {
+	p, _ := NewPicrPuzzleFromGoal(str2Map(`##..
+                                           #..#
+                                           .##.
+                                           ....`))
+	flips, q, err := p.SuggestFlips(PicrRepairOptions{})
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if len(flips) != 1 || flips[0] != (PicrCell{Row: 1, Col: 1, Value: Gap}) {
+		t.Errorf(`unexpected flips: %v`, flips)
+	}
+	if r, _ := q.Search(2); len(r.Solutions) != 1 || !areSlices2Equal(r.Solutions[0], q.Goal) {
+		t.Errorf(`flipped puzzle not unique: %v`, q.Goal)
+	}
+	p = probeGoalPuzzle()
+	flips, q, err = p.SuggestFlips(PicrRepairOptions{LineSolvable: true})
+	if err != nil || len(flips) != 1 {
+		t.Fatalf(`unexpected flips: %v %v`, flips, err)
+	}
+	if _, err := picrPropagate(q, nil); err != nil {
+		t.Errorf(`flipped puzzle not line solvable: %v`, err)
+	}
+	if flips, q, err = horsePuzzle().SuggestFlips(PicrRepairOptions{}); err != ErrPicrNoGoal {
+		t.Errorf(`unexpected result: %v %v %v`, flips, q, err)
+	}
+}
+
+func TestPicrRepairFail(t *testing.T) {
+	// Ambiguous spots apart from each other need a given each.
+	p, _ := NewPicrPuzzleFromGoal(str2Map(`#..#.#..#.
+                                           .#.#..#.#.
+                                           ..........
+                                           #.........
+                                           .#........`))
+	if _, err := p.SuggestGivens(PicrRepairOptions{MaxCells: 1}); err != ErrPicrNoRepair {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+	if givens, err := p.SuggestGivens(PicrRepairOptions{}); err != nil || len(givens) < 2 {
+		t.Errorf(`unexpected givens: %v %v`, givens, err)
+	}
+	p.Goal[0][0] = Gap
+	if _, err := p.SuggestGivens(PicrRepairOptions{}); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
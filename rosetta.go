@@ -0,0 +1,77 @@
+package picross
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseRosetta reads a puzzle in the letter notation of the Rosetta Code nonogram task:
+// a line with the row clues followed by a line with the column clues,
+// clues separated by spaces and each run length written as a letter ('A' is 1, 'B' is 2 and so on).
+func ParseRosetta(r io.Reader) (*PicrPuzzle, error) {
+	lines := make([]string, 0, 2)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("Rosetta: expected 2 lines of clues, got %d", len(lines))
+	}
+	axes := make([][][]uint, 2)
+	for i, line := range lines {
+		for _, field := range strings.Fields(line) {
+			clue := make([]uint, 0, len(field))
+			for _, c := range field {
+				if c < 'A' || c > 'Z' {
+					return nil, fmt.Errorf("Rosetta: unexpected character %q in clue %q", c, field)
+				}
+				clue = append(clue, uint(c-'A'+1))
+			}
+			axes[i] = append(axes[i], clue)
+		}
+	}
+	p := &PicrPuzzle{RowClues: axes[0], ColClues: axes[1]}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FormatRosetta writes a puzzle in the letter notation of the Rosetta Code nonogram task.
+// The notation has no room for empty lines nor for run lengths above 26.
+func FormatRosetta(w io.Writer, p *PicrPuzzle) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	for _, clues := range [][][]uint{p.RowClues, p.ColClues} {
+		fields := make([]string, len(clues))
+		for i, clue := range clues {
+			var b strings.Builder
+			for _, v := range clue {
+				if v == 0 {
+					continue
+				}
+				if v > 26 {
+					return fmt.Errorf("Rosetta: run length %d cannot be written as a letter", v)
+				}
+				b.WriteByte(byte('A' + v - 1))
+			}
+			if b.Len() == 0 {
+				return errors.New("Rosetta: empty lines cannot be written")
+			}
+			fields[i] = b.String()
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
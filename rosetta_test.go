@@ -0,0 +1,37 @@
+package picross
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseRosetta(t *testing.T) {
+	p, err := ParseRosetta(strings.NewReader("C AA D C AA\nA E AB C B\n"))
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	h := horsePuzzle()
+	if !areSlices2Equal(p.RowClues, h.RowClues) || !areSlices2Equal(p.ColClues, h.ColClues) {
+		t.Errorf(`clues mismatch: %v %v`, p.RowClues, p.ColClues)
+	}
+	var buf bytes.Buffer
+	if err := FormatRosetta(&buf, p); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if buf.String() != "C AA D C AA\nA E AB C B\n" {
+		t.Errorf(`unexpected output: %q`, buf.String())
+	}
+}
+
+func TestParseRosettaFail(t *testing.T) {
+	for _, s := range []string{"A\n", "A\nB\n", "a\nA\n", "A\nA\nA\n"} {
+		if _, err := ParseRosetta(strings.NewReader(s)); err == nil {
+			t.Errorf(`unexpected success for %q`, s)
+		}
+	}
+	p := &PicrPuzzle{RowClues: [][]uint{{}}, ColClues: [][]uint{{}}}
+	if err := FormatRosetta(&bytes.Buffer{}, p); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
@@ -0,0 +1,214 @@
+package picross
+
+import (
+	"errors"
+)
+
+// ErrPicrAmbiguous reports a puzzle with more than one solution.
+var ErrPicrAmbiguous = errors.New("PicrSearch: more than one solution")
+
+// PicrSearchResult holds the solutions found by a search, together with the effort it took.
+type PicrSearchResult struct {
+	// Solutions found, up to the requested limit.
+	Solutions [][][]CellState
+	// Nodes is the amount of line logic propagations performed.
+	Nodes uint
+	// Guesses is the amount of cells that had to be guessed.
+	Guesses uint
+	// Backtracks is the amount of guesses that led to a contradiction.
+	Backtracks uint
+	// MaxDepth is the deepest nesting of guesses.
+	MaxDepth uint
+}
+
+// PicrProbeResult holds the grid reached by line logic helped by probing.
+type PicrProbeResult struct {
+	Grid [][]CellState
+	// Probes is the amount of cell values tried.
+	Probes uint
+	// Forced is the amount of cells determined because their other value led to a contradiction.
+	Forced uint
+}
+
+// isPicrContradiction tells whether a solver error means that the clues cannot be honored,
+// as opposed to line logic simply not being able to proceed.
+func isPicrContradiction(err error) bool {
+	return err != nil && !errors.Is(err, ErrPicrDubious)
+}
+
+// picrPropagate applies line logic to a puzzle, starting from a seed grid (which may be nil).
+// It returns the grid reached and the error of the solver, which is ErrPicrDubious when line logic stalls.
+func picrPropagate(p *PicrPuzzle, seed [][]CellState) ([][]CellState, error) {
+	return picrPropagateWith(p, seed, nil)
+}
+
+// picrPropagateWith is picrPropagate, calling `setup` (when not nil) on the solver before it starts.
+func picrPropagateWith(p *PicrPuzzle, seed [][]CellState, setup func(s *PicrSolver)) ([][]CellState, error) {
+	s, err := p.NewSolver(nil)
+	if err != nil {
+		return nil, err
+	}
+	if setup != nil {
+		setup(s)
+	}
+	if seed != nil {
+		if err := s.row.work(seed); err != nil {
+			return nil, err
+		}
+	}
+	err = s.solve()
+	return s.getState(), err
+}
+
+// picrCopyMap returns a deep copy of a grid.
+func picrCopyMap(mat [][]CellState) [][]CellState {
+	ans := make([][]CellState, len(mat))
+	for i, row := range mat {
+		ans[i] = make([]CellState, len(row))
+		copy(ans[i], row)
+	}
+	return ans
+}
+
+// Search enumerates the solutions of a puzzle, up to `limit` of them,
+// by guessing cells wherever line logic stalls and backtracking on contradictions.
+// A limit of 2 is enough to tell whether the solution is unique.
+func (p *PicrPuzzle) Search(limit int) (*PicrSearchResult, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	r := &PicrSearchResult{}
+	if err := p.search(nil, 0, limit, r, nil); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// errPicrSearchBudget stops a search that took more nodes than allowed.
+var errPicrSearchBudget = errors.New("PicrSearch: node budget exhausted")
+
+// picrSearchHooks observe the progress of a search, and may bound it; any of them may be nil.
+type picrSearchHooks struct {
+	// maxNodes, when not zero, stops the search with errPicrSearchBudget past that many nodes.
+	maxNodes uint
+	// solver is called with the solver of every propagation before it starts.
+	solver func(s *PicrSolver)
+	// guess is called when a cell is guessed, and backtrack when the search gives up a guess
+	// to look elsewhere, either because it led to a contradiction or to find more solutions.
+	guess     func(row, col int, v CellState, depth uint)
+	backtrack func(row, col int, v CellState, depth uint)
+	// solution is called with every solution found.
+	solution func(grid [][]CellState)
+}
+
+// search explores the solutions reachable from a seed grid, guessing `depth` cells deep.
+// The hooks, when not nil, observe its progress.
+func (p *PicrPuzzle) search(seed [][]CellState, depth uint, limit int, r *PicrSearchResult, hooks *picrSearchHooks) error {
+	if hooks == nil {
+		hooks = &picrSearchHooks{}
+	}
+	r.Nodes += 1
+	if hooks.maxNodes > 0 && r.Nodes > hooks.maxNodes {
+		return errPicrSearchBudget
+	}
+	if depth > r.MaxDepth {
+		r.MaxDepth = depth
+	}
+	grid, err := picrPropagateWith(p, seed, hooks.solver)
+	if isPicrContradiction(err) {
+		return nil
+	}
+	if err == nil {
+		r.Solutions = append(r.Solutions, grid)
+		if hooks.solution != nil {
+			hooks.solution(grid)
+		}
+		return nil
+	}
+	row, col := picrFirstAny(grid)
+	for _, v := range []CellState{Fill, Gap} {
+		if len(r.Solutions) >= limit {
+			return nil
+		}
+		guess := picrCopyMap(grid)
+		guess[row][col] = v
+		r.Guesses += 1
+		if hooks.guess != nil {
+			hooks.guess(row, col, v, depth+1)
+		}
+		found := len(r.Solutions)
+		if err := p.search(guess, depth+1, limit, r, hooks); err != nil {
+			return err
+		}
+		if len(r.Solutions) == found {
+			r.Backtracks += 1
+		}
+		if hooks.backtrack != nil && len(r.Solutions) < limit {
+			hooks.backtrack(row, col, v, depth+1)
+		}
+	}
+	return nil
+}
+
+// picrFirstAny returns the position of the first unknown cell of a grid, in reading order.
+func picrFirstAny(grid [][]CellState) (int, int) {
+	for i, row := range grid {
+		for j, v := range row {
+			if v == Any {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+// Probe applies line logic to a puzzle, and then repeatedly tries both values of every unknown cell:
+// when one value leads line logic to a contradiction the cell takes the other one,
+// and line logic resumes from there.
+// The returned grid still has unknown cells when probing is not enough to solve the puzzle.
+// An error is returned only when the clues contradict each other.
+func (p *PicrPuzzle) Probe() (*PicrProbeResult, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	grid, err := picrPropagate(p, nil)
+	if isPicrContradiction(err) {
+		return nil, err
+	}
+	r := &PicrProbeResult{Grid: grid}
+	for progress := err != nil; progress; {
+		progress = false
+	picrProbeCells:
+		for i, row := range r.Grid {
+			for j, v := range row {
+				if v != Any {
+					continue
+				}
+				for _, guess := range []CellState{Fill, Gap} {
+					seed := picrCopyMap(r.Grid)
+					seed[i][j] = guess
+					r.Probes += 1
+					if _, err := picrPropagate(p, seed); !isPicrContradiction(err) {
+						continue
+					}
+					seed[i][j] = Fill
+					if guess == Fill {
+						seed[i][j] = Gap
+					}
+					r.Forced += 1
+					grid, err := picrPropagate(p, seed)
+					if isPicrContradiction(err) {
+						return nil, err
+					}
+					r.Grid = grid
+					progress = err != nil
+					break picrProbeCells
+				}
+			}
+		}
+	}
+	return r, nil
+}
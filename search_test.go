@@ -0,0 +1,93 @@
+package picross
+
+import (
+	"testing"
+)
+
+// probePuzzle is stalled by line logic but solved by probing.
+func probePuzzle() *PicrPuzzle {
+	p, _ := NewPicrPuzzle(
+		[][]uint{{4}, {1}, {1}, {1, 1}, {1}, {2, 1}, {2}},
+		[][]uint{{1, 2}, {1, 1, 2}, {2}, {1, 1, 1}, {2}})
+	return p
+}
+
+func TestPicrSearch(t *testing.T) {
+	r, err := horsePuzzle().Search(2)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if len(r.Solutions) != 1 || r.Guesses != 0 || r.Nodes != 1 {
+		t.Errorf(`unexpected result: %+v`, r)
+	}
+	if !areSlices2Equal(r.Solutions[0], str2Map(`###..
+                                                 .#..#
+                                                 .####
+                                                 .###.
+                                                 .#.#.`)) {
+		t.Errorf(`unexpected solution: %v`, r.Solutions[0])
+	}
+	p, _ := NewPicrPuzzle([][]uint{{1}, {1}}, [][]uint{{1}, {1}})
+	r, err = p.Search(5)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if len(r.Solutions) != 2 || r.Guesses != 2 || r.MaxDepth != 1 {
+		t.Errorf(`unexpected result: %+v`, r)
+	}
+	if r, _ = p.Search(1); len(r.Solutions) != 1 {
+		t.Errorf(`unexpected amount of solutions: %v`, len(r.Solutions))
+	}
+	p, _ = NewPicrPuzzle([][]uint{{2}, {2}}, [][]uint{{1}, {2}})
+	if r, err = p.Search(2); err != nil || len(r.Solutions) != 0 {
+		t.Errorf(`unexpected result: %+v %v`, r, err)
+	}
+	if _, err = (&PicrPuzzle{}).Search(2); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
+
+func TestPicrSearchProbePuzzle(t *testing.T) {
+	r, err := probePuzzle().Search(2)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if len(r.Solutions) != 1 || r.Guesses < 1 {
+		t.Errorf(`unexpected result: %+v`, r)
+	}
+}
+
+func TestPicrProbe(t *testing.T) {
+	p := probePuzzle()
+	if _, err := picrPropagate(p, nil); err != ErrPicrDubious {
+		t.Fatalf(`line logic unexpectedly succeeded: %v`, err)
+	}
+	r, err := p.Probe()
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if r.Forced < 1 || r.Probes < r.Forced {
+		t.Errorf(`unexpected result: %+v`, r)
+	}
+	if !areSlices2Equal(r.Grid, str2Map(`####.
+                                         ..#..
+                                         ...#.
+                                         .#..#
+                                         ....#
+                                         ##.#.
+                                         ##...`)) {
+		t.Errorf(`unexpected grid: %v`, r.Grid)
+	}
+	r, err = horsePuzzle().Probe()
+	if err != nil || r.Probes != 0 {
+		t.Errorf(`unexpected result: %+v %v`, r, err)
+	}
+	p, _ = NewPicrPuzzle([][]uint{{1}, {1}}, [][]uint{{1}, {1}})
+	if r, err = p.Probe(); err != nil || r.Forced != 0 || r.Grid[0][0] != Any {
+		t.Errorf(`unexpected result: %+v %v`, r, err)
+	}
+	p, _ = NewPicrPuzzle([][]uint{{2}, {2}}, [][]uint{{1}, {2}})
+	if _, err = p.Probe(); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
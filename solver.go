@@ -2,6 +2,8 @@ package picross
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -26,6 +28,35 @@ func (c CellState) String() string {
 	return "(error: unexpected)"
 }
 
+// MarshalText writes a cell state as "any", "gap" or "fill", as it appears in JSON reports.
+func (c CellState) MarshalText() ([]byte, error) {
+	switch c {
+	case Any, Gap, Fill:
+		return []byte(strings.ToLower(c.String())), nil
+	}
+	return nil, fmt.Errorf("CellState: unexpected value %d", uint(c))
+}
+
+// UnmarshalText reads a cell state written by MarshalText.
+func (c *CellState) UnmarshalText(text []byte) error {
+	for _, v := range []CellState{Any, Gap, Fill} {
+		if strings.EqualFold(string(text), v.String()) {
+			*c = v
+			return nil
+		}
+	}
+	return fmt.Errorf("CellState: unexpected value %q", text)
+}
+
+var (
+	// ErrPicrNonsenseHint reports a hint that contradicts what a line already knows.
+	ErrPicrNonsenseHint = errors.New("PicrWorker: nonsense hint")
+	// ErrPicrNoSolution reports a line whose clue cannot be honored by its hint.
+	ErrPicrNoSolution = errors.New("PicrWorker: no solution")
+	// ErrPicrDubious reports a puzzle that line logic alone cannot take any further.
+	ErrPicrDubious = errors.New("PicrSolver: dubious puzzle")
+)
+
 type PicrWorkerNotification struct {
 	position uint
 	value    CellState
@@ -37,6 +68,8 @@ type PicrWorker struct {
 	clue     []uint
 	hint     []CellState
 	notifCh  chan PicrWorkerNotification
+	// free, when set, relaxes the clue: the worker takes hints but deduces nothing from them.
+	free bool
 }
 
 func NewPicrWorker(depth uint, clue []uint, notifCh chan PicrWorkerNotification) (*PicrWorker, error) {
@@ -65,7 +98,7 @@ func (w *PicrWorker) work(hint []CellState) error {
 			continue
 		}
 		if v != w.hint[i] {
-			return errors.New("PicrWorker: nonsense hint")
+			return ErrPicrNonsenseHint
 		}
 	}
 	anyChange := false
@@ -81,7 +114,7 @@ func (w *PicrWorker) work(hint []CellState) error {
 		}
 		w.hint[i] = v
 	}
-	if !anyChange && w.isPrimed {
+	if w.free || (!anyChange && w.isPrimed) {
 		return nil
 	}
 	w.isPrimed = true
@@ -110,7 +143,7 @@ emergeHintPermutations:
 		}
 	}
 	if !initialized {
-		return errors.New("PicrWorker: no solution")
+		return ErrPicrNoSolution
 	}
 	for i, v := range pivot {
 		if dirty[i] {
@@ -138,6 +171,10 @@ type PicrAxisNotification struct {
 type PicrAxis struct {
 	workers []*PicrWorker
 	notifCh chan PicrAxisNotification
+	// lineHook, when set, is called by work for every worker, in index order,
+	// with the hint that the worker got and the hint that it produced.
+	// When some workers fail it is called for the failing ones only, with their error and no hint produced.
+	lineHook func(idx uint, before []CellState, after []CellState, err error)
 }
 
 func NewPicrAxis(depth uint, clues [][]uint, notifCh chan PicrAxisNotification) (*PicrAxis, error) {
@@ -176,14 +213,32 @@ func (a *PicrAxis) work(hint [][]CellState) error {
 		panic("hint length mismatch")
 	}
 	g := new(errgroup.Group)
+	errs := make([]error, len(a.workers))
 	for i, w := range a.workers {
 		i := i
 		w := w
-		g.Go(func() error { return w.work(hint[i]) })
+		g.Go(func() error {
+			errs[i] = w.work(hint[i])
+			return errs[i]
+		})
 	}
 	if err := g.Wait(); err != nil {
+		if a.lineHook != nil {
+			for i, werr := range errs {
+				if werr != nil {
+					a.lineHook(uint(i), hint[i], nil, werr)
+				}
+			}
+		}
 		return err
 	}
+	if a.lineHook != nil {
+		for i, w := range a.workers {
+			after := make([]CellState, len(w.getHint()))
+			copy(after, w.getHint())
+			a.lineHook(uint(i), hint[i], after, nil)
+		}
+	}
 	if a.notifCh != nil {
 		for i, w := range a.workers {
 			ch := w.getNotifCh()
@@ -201,16 +256,35 @@ func (a *PicrAxis) work(hint [][]CellState) error {
 	return nil
 }
 
+// PicrSolverNotification reports a cell determined by a PicrSolver.
 type PicrSolverNotification struct {
 	row  uint
 	col  uint
 	mark bool
 }
 
+// Row returns the 1-based row of the cell.
+func (n PicrSolverNotification) Row() uint {
+	return n.row
+}
+
+// Col returns the 1-based column of the cell.
+func (n PicrSolverNotification) Col() uint {
+	return n.col
+}
+
+// Mark tells whether the cell is filled; otherwise it is a gap.
+func (n PicrSolverNotification) Mark() bool {
+	return n.mark
+}
+
 type PicrSolver struct {
 	row     *PicrAxis
 	col     *PicrAxis
 	notifCh chan PicrSolverNotification
+	// roundHook, when set, is called at the end of each round of solve,
+	// after the notifications of the round have been sent.
+	roundHook func()
 }
 
 func NewPicrSolver(rowClues [][]uint, colClues [][]uint, notifCh chan PicrSolverNotification) (*PicrSolver, error) {
@@ -233,6 +307,17 @@ func (s *PicrSolver) getState() [][]CellState {
 	return s.row.getHint()
 }
 
+// OnRound sets a function to be called at the end of each round of Solve,
+// after the notifications of the round have been sent.
+func (s *PicrSolver) OnRound(hook func()) {
+	s.roundHook = hook
+}
+
+// State returns the grid as determined so far, indexed by row and then by column.
+func (s *PicrSolver) State() [][]CellState {
+	return s.getState()
+}
+
 func picrTranspose(mat [][]CellState) [][]CellState {
 	ans := make([][]CellState, 0)
 	for rowIdx := range mat[0] {
@@ -258,6 +343,13 @@ func picrCountAny(mat [][]CellState) uint {
 	return ans
 }
 
+// Solve determines the cells of the puzzle by line logic.
+// It fails with ErrPicrDubious when line logic cannot determine every cell,
+// or with another error when the clues contradict each other.
+func (s *PicrSolver) Solve() error {
+	return s.solve()
+}
+
 func (s *PicrSolver) solve() error {
 	n_unknown := picrCountAny(s.row.getHint())
 	for n_unknown > 0 {
@@ -279,9 +371,12 @@ func (s *PicrSolver) solve() error {
 				}
 			}
 		}
+		if s.roundHook != nil {
+			s.roundHook()
+		}
 		n := picrCountAny(s.row.getHint())
 		if n == n_unknown {
-			return errors.New("PicrSolver: dubious puzzle")
+			return ErrPicrDubious
 		}
 		n_unknown = n
 	}
@@ -246,6 +246,36 @@ testPicrSolverNotifVerifyGot:
 	}
 }
 
+func TestPicrSolverOnRound(t *testing.T) {
+	ch := make(chan PicrSolverNotification, 25)
+	solver, _ := NewPicrSolver(
+		[][]uint{{3}, {1, 1}, {4}, {3}, {1, 1}},
+		[][]uint{{1}, {5}, {1, 2}, {3}, {2}},
+		ch)
+	goal := str2Map(`###..
+                     .#..#
+                     .####
+                     .###.
+                     .#.#.`)
+	var rounds, notified uint
+	solver.OnRound(func() {
+		rounds += 1
+		notified += uint(len(ch))
+		for len(ch) > 0 {
+			n := <-ch
+			if n.Row() < 1 || n.Row() > 5 || n.Col() < 1 || n.Col() > 5 || n.Mark() != (goal[n.Row()-1][n.Col()-1] == Fill) {
+				t.Errorf(`unexpected notification: %v`, n)
+			}
+		}
+	})
+	if err := solver.Solve(); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if rounds < 1 || notified != 25 {
+		t.Errorf(`unexpected rounds: %v, notified cells: %v`, rounds, notified)
+	}
+}
+
 func TestPicrSolverNonSquare(t *testing.T) {
 	// A non-square puzzle
 	checkPicrSolver(t,
@@ -348,3 +378,23 @@ func TestPicrSolver4030Peacock(t *testing.T) {
                  ...####..####...............###.###.....
                  ...........................#..##.###....`))
 }
+
+func TestCellStateText(t *testing.T) {
+	for _, v := range []CellState{Any, Gap, Fill} {
+		text, err := v.MarshalText()
+		if err != nil {
+			t.Errorf(`%v: unexpected error: %v`, v, err)
+		}
+		var w CellState
+		if err := w.UnmarshalText(text); err != nil || w != v {
+			t.Errorf(`%v: round trip through %q gave %v, %v`, v, text, w, err)
+		}
+	}
+	if _, err := CellState(7).MarshalText(); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+	var w CellState
+	if err := w.UnmarshalText([]byte(`maybe`)); err == nil {
+		t.Errorf(`unexpected success`)
+	}
+}
@@ -0,0 +1,116 @@
+package picross
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseTathamGameID parses a game ID of the "Pattern" puzzle
+// from Simon Tatham's Portable Puzzle Collection, such as `5x5:1/5/1.2/3/2/3/1.1/4/3/1.1`.
+// The parameters part is `WIDTHxHEIGHT` (or a single number for square puzzles),
+// and the description lists the clues of all columns, left to right,
+// followed by the clues of all rows, top to bottom.
+// Lines are separated by '/', run lengths by '.', and an empty line is written as '0'.
+// Any immutable squares following a ',' in the description are ignored.
+// Random seed IDs (`5x5#12345`) cannot be parsed, as they require the generator of the collection.
+func ParseTathamGameID(id string) (*PicrPuzzle, error) {
+	id = strings.TrimSpace(id)
+	if strings.Contains(id, "#") {
+		return nil, errors.New("Tatham: random seed game IDs are not supported")
+	}
+	colon := strings.IndexByte(id, ':')
+	if colon < 0 {
+		return nil, errors.New("Tatham: missing game description")
+	}
+	width, height, err := parseTathamParams(id[:colon])
+	if err != nil {
+		return nil, err
+	}
+	desc := id[colon+1:]
+	if comma := strings.IndexByte(desc, ','); comma >= 0 {
+		desc = desc[:comma]
+	}
+	lines := strings.Split(desc, "/")
+	if uint(len(lines)) != width+height {
+		return nil, fmt.Errorf("Tatham: expected %d clues for a %dx%d puzzle, got %d", width+height, width, height, len(lines))
+	}
+	clues := make([][]uint, len(lines))
+	for i, line := range lines {
+		clue, err := parseTathamClue(line)
+		if err != nil {
+			return nil, fmt.Errorf("Tatham: clue %d: %v", i+1, err)
+		}
+		clues[i] = clue
+	}
+	p := &PicrPuzzle{ColClues: clues[:width], RowClues: clues[width:]}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FormatTathamGameID generates a "Pattern" game ID for the clues of a puzzle,
+// in the descriptive form understood by ParseTathamGameID.
+func FormatTathamGameID(p *PicrPuzzle) (string, error) {
+	if err := p.validate(); err != nil {
+		return "", err
+	}
+	lines := make([]string, 0, p.Width()+p.Height())
+	for _, clue := range p.ColClues {
+		lines = append(lines, formatTathamClue(clue))
+	}
+	for _, clue := range p.RowClues {
+		lines = append(lines, formatTathamClue(clue))
+	}
+	return fmt.Sprintf("%dx%d:%s", p.Width(), p.Height(), strings.Join(lines, "/")), nil
+}
+
+// parseTathamParams decodes the `WIDTHxHEIGHT` parameters of a game ID.
+func parseTathamParams(params string) (uint, uint, error) {
+	ws, hs := params, params
+	if x := strings.IndexByte(params, 'x'); x >= 0 {
+		ws, hs = params[:x], params[x+1:]
+	}
+	width, err := strconv.ParseUint(ws, 10, 0)
+	if err != nil || width < 1 {
+		return 0, 0, fmt.Errorf("Tatham: invalid width in parameters %q", params)
+	}
+	height, err := strconv.ParseUint(hs, 10, 0)
+	if err != nil || height < 1 {
+		return 0, 0, fmt.Errorf("Tatham: invalid height in parameters %q", params)
+	}
+	return uint(width), uint(height), nil
+}
+
+// parseTathamClue decodes the '.' separated run lengths of a single line.
+func parseTathamClue(s string) ([]uint, error) {
+	ans := make([]uint, 0)
+	for _, field := range strings.Split(s, ".") {
+		v, err := strconv.ParseUint(field, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run length %q", field)
+		}
+		if v == 0 {
+			continue
+		}
+		ans = append(ans, uint(v))
+	}
+	return ans, nil
+}
+
+// formatTathamClue encodes the run lengths of a single line.
+func formatTathamClue(clue []uint) string {
+	fields := make([]string, 0, len(clue))
+	for _, v := range clue {
+		if v == 0 {
+			continue
+		}
+		fields = append(fields, strconv.FormatUint(uint64(v), 10))
+	}
+	if len(fields) == 0 {
+		return "0"
+	}
+	return strings.Join(fields, ".")
+}
@@ -0,0 +1,63 @@
+package picross
+
+import (
+	"testing"
+)
+
+func TestParseTathamGameID(t *testing.T) {
+	// 5x5 horse
+	p, err := ParseTathamGameID(`5x5:1/5/1.2/3/2/3/1.1/4/3/1.1`)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	expRows := [][]uint{{3}, {1, 1}, {4}, {3}, {1, 1}}
+	expCols := [][]uint{{1}, {5}, {1, 2}, {3}, {2}}
+	if !areSlices2Equal(p.RowClues, expRows) {
+		t.Errorf(`row clues mismatch: expected %v, got %v`, expRows, p.RowClues)
+	}
+	if !areSlices2Equal(p.ColClues, expCols) {
+		t.Errorf(`column clues mismatch: expected %v, got %v`, expCols, p.ColClues)
+	}
+	s, _ := p.NewSolver(nil)
+	if err := s.solve(); err != nil {
+		t.Errorf(`unexpected solver error: %v`, err)
+	}
+}
+
+func TestParseTathamGameIDFail(t *testing.T) {
+	for _, id := range []string{
+		`5x5#12345`,
+		`5x5`,
+		`0x5:`,
+		`2x2:1/1/1`,
+		`2x2:1/1/1/a`,
+		`2x2:1/1/3/1`,
+		`2x1:1.1/0/1`,
+	} {
+		if _, err := ParseTathamGameID(id); err == nil {
+			t.Errorf(`unexpected success for %q`, id)
+		}
+	}
+}
+
+func TestFormatTathamGameID(t *testing.T) {
+	p, err := NewPicrPuzzle([][]uint{{2}, {}, {1, 1}}, [][]uint{{1, 1}, {1}, {1, 1}})
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	expected := `3x3:1.1/1/1.1/2/0/1.1`
+	got, err := FormatTathamGameID(p)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if got != expected {
+		t.Errorf(`mismatch: expected %v, got %v`, expected, got)
+	}
+	q, err := ParseTathamGameID(got)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if !areSlices2Equal(q.RowClues, p.RowClues) || !areSlices2Equal(q.ColClues, p.ColClues) {
+		t.Errorf(`round trip mismatch: expected %v %v, got %v %v`, p.RowClues, p.ColClues, q.RowClues, q.ColClues)
+	}
+}
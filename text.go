@@ -0,0 +1,221 @@
+package picross
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PicrTextError reports a syntax or consistency error of the text format, at a 1-based position.
+type PicrTextError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *PicrTextError) Error() string {
+	return fmt.Sprintf("text: line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// ParsePicrText reads a puzzle in the human editable text format:
+//
+//	// The horse of the tests.
+//	title: Horse
+//	rows: 3 | 1 1 | 4 | 3 | 1 1
+//	cols: 1 | 5 | 1 2 | 3 | 2
+//
+//	###..
+//	.#..#
+//	.####
+//	.###.
+//	.#.#.
+//
+// Lines starting with "//" are comments.
+// Each clue block lists the clues of the rows (columns) separated by '|',
+// where '0' stands for an empty line; a block may be split across repeated `rows:` (`cols:`) lines.
+// The optional solution picture follows, with '#' for filled cells and '.' for gaps
+// (spaces are ignored); it must match the clues, and the clues are derived from it when omitted.
+func ParsePicrText(r io.Reader) (*PicrPuzzle, error) {
+	sc := bufio.NewScanner(r)
+	p := &PicrPuzzle{}
+	var rows, cols [][]uint
+	var picture [][]CellState
+	var pictureLines []int
+	// cellCols holds the text column of each cell of the first picture row.
+	var cellCols []int
+	lineNo := 0
+	for sc.Scan() {
+		lineNo += 1
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		col := strings.Index(line, trimmed) + 1
+		if colon := strings.IndexByte(trimmed, ':'); colon >= 0 && picture == nil {
+			key := strings.TrimSpace(trimmed[:colon])
+			value := trimmed[colon+1:]
+			valueCol := col + colon + 1
+			var err error
+			switch key {
+			case "title":
+				p.Title = strings.TrimSpace(value)
+			case "rows":
+				rows, err = parsePicrTextClues(rows, value, lineNo, valueCol)
+			case "cols":
+				cols, err = parsePicrTextClues(cols, value, lineNo, valueCol)
+			default:
+				err = &PicrTextError{lineNo, col, fmt.Sprintf("unknown key %q", key)}
+			}
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		row := make([]CellState, 0, len(trimmed))
+		positions := make([]int, 0, len(trimmed))
+		for i, c := range line {
+			switch c {
+			case ' ', '\t':
+			case '#':
+				row = append(row, Fill)
+				positions = append(positions, i+1)
+			case '.':
+				row = append(row, Gap)
+				positions = append(positions, i+1)
+			default:
+				return nil, &PicrTextError{lineNo, i + 1, fmt.Sprintf("unexpected character %q in picture", c)}
+			}
+		}
+		if picture != nil && len(row) != len(picture[0]) {
+			return nil, &PicrTextError{lineNo, col, fmt.Sprintf("picture row has %d cells, expected %d", len(row), len(picture[0]))}
+		}
+		if picture == nil {
+			cellCols = positions
+		}
+		picture = append(picture, row)
+		pictureLines = append(pictureLines, lineNo)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if picture != nil {
+		derived, _ := NewPicrPuzzleFromGoal(picture)
+		if rows == nil && cols == nil {
+			derived.Title = p.Title
+			return derived, nil
+		}
+		if len(picture) != len(rows) || len(picture[0]) != len(cols) {
+			return nil, &PicrTextError{pictureLines[0], 1, fmt.Sprintf("picture is %dx%d, clues describe %dx%d", len(picture[0]), len(picture), len(cols), len(rows))}
+		}
+		for i, clue := range derived.RowClues {
+			if !picrSameClue(clue, rows[i]) {
+				return nil, &PicrTextError{pictureLines[i], 1, fmt.Sprintf("picture row %d does not match clue %v", i+1, rows[i])}
+			}
+		}
+		for j, clue := range derived.ColClues {
+			if !picrSameClue(clue, cols[j]) {
+				return nil, &PicrTextError{pictureLines[0], cellCols[j], fmt.Sprintf("picture column %d does not match clue %v", j+1, cols[j])}
+			}
+		}
+		p.Goal = picture
+	}
+	p.RowClues, p.ColClues = rows, cols
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parsePicrTextClues appends the '|' separated clues of a clue block to `clues`.
+func parsePicrTextClues(clues [][]uint, value string, lineNo, col int) ([][]uint, error) {
+	for _, group := range strings.Split(value, "|") {
+		clue := make([]uint, 0)
+		offset := 0
+		for _, field := range strings.Fields(group) {
+			at := strings.Index(group[offset:], field) + offset
+			offset = at + len(field)
+			v, err := strconv.ParseUint(field, 10, 0)
+			if err != nil {
+				return nil, &PicrTextError{lineNo, col + at, fmt.Sprintf("invalid run length %q", field)}
+			}
+			if v > 0 {
+				clue = append(clue, uint(v))
+			}
+		}
+		if len(strings.Fields(group)) == 0 {
+			return nil, &PicrTextError{lineNo, col, "empty clue (write 0 for an empty line)"}
+		}
+		clues = append(clues, clue)
+		col += len(group) + 1
+	}
+	return clues, nil
+}
+
+// picrSameClue compares clues disregarding zero run lengths.
+func picrSameClue(a []uint, b []uint) bool {
+	return picrFormatClue(a) == picrFormatClue(b)
+}
+
+// picrFormatClue writes a clue as space separated run lengths, '0' for an empty line.
+func picrFormatClue(clue []uint) string {
+	fields := make([]string, 0, len(clue))
+	for _, v := range clue {
+		if v > 0 {
+			fields = append(fields, strconv.FormatUint(uint64(v), 10))
+		}
+	}
+	if len(fields) == 0 {
+		return "0"
+	}
+	return strings.Join(fields, " ")
+}
+
+// FormatPicrText writes a puzzle in the text format, including its goal as a picture when present.
+func FormatPicrText(w io.Writer, p *PicrPuzzle) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	if p.Title != "" {
+		fmt.Fprintf(bw, "title: %s\n", p.Title)
+	}
+	for _, block := range []struct {
+		key   string
+		clues [][]uint
+	}{{"rows", p.RowClues}, {"cols", p.ColClues}} {
+		fields := make([]string, len(block.clues))
+		for i, clue := range block.clues {
+			fields[i] = picrFormatClue(clue)
+		}
+		fmt.Fprintf(bw, "%s: %s\n", block.key, strings.Join(fields, " | "))
+	}
+	if p.Goal != nil {
+		fmt.Fprintln(bw)
+		for _, row := range p.Goal {
+			fmt.Fprintln(bw, picrFormatRow(row))
+		}
+	}
+	return bw.Flush()
+}
+
+var (
+	picrTextSniffRe   = regexp.MustCompile(`(?m)^\s*(rows|cols)\s*:`)
+	picrGridSniffRe   = regexp.MustCompile(`^[#. \t\r\n]*$`)
+	picrGridAnyRuneRe = regexp.MustCompile(`[#.]`)
+)
+
+func init() {
+	RegisterPicrFormat(PicrFormat{
+		Name:       "text",
+		Extensions: []string{".txt", ".picross"},
+		Sniff: func(head []byte) bool {
+			return picrTextSniffRe.Match(head) || (picrGridSniffRe.Match(head) && picrGridAnyRuneRe.Match(head))
+		},
+		Decode: ParsePicrText,
+		Encode: FormatPicrText,
+	})
+}
@@ -0,0 +1,82 @@
+package picross
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParsePicrText(t *testing.T) {
+	text := `// The horse of the tests.
+title: Horse
+rows: 3 | 1 1 | 4
+rows: 3 | 1 1
+cols: 1 | 5 | 1 2 | 3 | 2
+
+###..
+.#..#
+.####
+.###.
+.#.#.
+`
+	p, err := ParsePicrText(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	h := horsePuzzle()
+	if p.Title != `Horse` || !areSlices2Equal(p.RowClues, h.RowClues) || !areSlices2Equal(p.ColClues, h.ColClues) {
+		t.Errorf(`puzzle mismatch: %v`, p)
+	}
+	if p.Goal == nil || picrFormatRow(p.Goal[4]) != `.#.#.` {
+		t.Errorf(`goal mismatch: %v`, p.Goal)
+	}
+	var buf bytes.Buffer
+	if err := FormatPicrText(&buf, p); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	expected := "title: Horse\nrows: 3 | 1 1 | 4 | 3 | 1 1\ncols: 1 | 5 | 1 2 | 3 | 2\n\n###..\n.#..#\n.####\n.###.\n.#.#.\n"
+	if buf.String() != expected {
+		t.Errorf(`unexpected output: %q`, buf.String())
+	}
+}
+
+func TestParsePicrTextGrid(t *testing.T) {
+	p, err := ParsePicrText(strings.NewReader("# # .\n. . .\n"))
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	expRows := [][]uint{{2}, {}}
+	if !areSlices2Equal(p.RowClues, expRows) || len(p.ColClues) != 3 {
+		t.Errorf(`clues mismatch: %v %v`, p.RowClues, p.ColClues)
+	}
+}
+
+func TestParsePicrTextFail(t *testing.T) {
+	checks := []struct {
+		text   string
+		line   int
+		column int
+	}{
+		{"rows: 1\ncolz: 1\n", 2, 1},
+		{"rows: 1 | x\ncols: 1\n", 1, 11},
+		{"rows: 1 |  | 1\n", 1, 10},
+		{"rows: 1\ncols: 1\n\n#\n#?\n", 5, 2},
+		{"rows: 1 | 1\ncols: 1\n\n#\n.\n", 5, 1},
+		{"rows: 1\ncols: 1 | 0\n\n.#\n", 4, 1},
+		// Columns of spaced pictures are reported at their cells.
+		{"rows: 1 | 1\ncols: 1 | 1\n\n  # .\n  # .\n", 4, 3},
+		{"rows: 1 | 1\ncols: 2 | 1\n\n  # .\n  # .\n", 4, 5},
+	}
+	for _, c := range checks {
+		_, err := ParsePicrText(strings.NewReader(c.text))
+		var te *PicrTextError
+		if !errors.As(err, &te) {
+			t.Errorf(`%q: expected a text error, got %v`, c.text, err)
+			continue
+		}
+		if te.Line != c.line || te.Column != c.column {
+			t.Errorf(`%q: expected position %d:%d, got %v`, c.text, c.line, c.column, te)
+		}
+	}
+}
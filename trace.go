@@ -0,0 +1,263 @@
+package picross
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Kinds of PicrTraceEvent.
+const (
+	PicrTraceStart = "start"
+	PicrTraceRound = "round"
+	PicrTraceLine  = "line"
+	PicrTraceCell  = "cell"
+	PicrTraceEnd   = "end"
+	// Only traces of a search have guesses and backtracks.
+	PicrTraceGuess     = "guess"
+	PicrTraceBacktrack = "backtrack"
+	PicrTraceSolution  = "solution"
+)
+
+// Final statuses of a traced solve.
+const (
+	PicrTraceSolved        = "solved"
+	PicrTraceContradiction = "contradiction"
+	PicrTraceStalled       = "stalled"
+)
+
+// PicrTraceEvent is a single step of a solve trace.
+// Rows, columns and line indexes are 1-based; lines are written in the notation of the tests.
+//
+//   - "start" opens the trace with the size and the clues of the puzzle.
+//   - "round" begins a solver round; the final check after the last round is a round of its own.
+//   - "line" reports a line examined by the solver: its axis ("row" or "col"), clue, and hint before and after.
+//     A line whose clue contradicts its hint has an error and no hint after.
+//   - "cell" reports a cell determined by the line examined just before.
+//   - "guess" sets a cell to a value the search is trying, `depth` guesses deep;
+//     the rounds of line logic that follow count from 1 again.
+//   - "backtrack" undoes the guess of a cell, bringing back the grid as it was before that guess.
+//   - "solution" reports that the grid as replayed so far is a solution, counting them from 1 in `index`.
+//   - "end" closes the trace with the final status and the error of the solver, if any.
+type PicrTraceEvent struct {
+	Event    string   `json:"event"`
+	Round    uint     `json:"round,omitempty"`
+	Width    uint     `json:"width,omitempty"`
+	Height   uint     `json:"height,omitempty"`
+	RowClues [][]uint `json:"rows,omitempty"`
+	ColClues [][]uint `json:"cols,omitempty"`
+	Axis     string   `json:"axis,omitempty"`
+	Index    uint     `json:"index,omitempty"`
+	Clue     []uint   `json:"clue,omitempty"`
+	Before   string   `json:"before,omitempty"`
+	After    string   `json:"after,omitempty"`
+	Row      uint     `json:"row,omitempty"`
+	Col      uint     `json:"col,omitempty"`
+	Value    string   `json:"value,omitempty"`
+	Depth    uint     `json:"depth,omitempty"`
+	Status   string   `json:"status,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// TracePicrSolve solves a puzzle by line logic, writing every step of the solver as JSON Lines.
+// The solver error, if any, is returned after the trace is complete.
+func TracePicrSolve(w io.Writer, p *PicrPuzzle) error {
+	return tracePicrWrite(w, func(emit func(PicrTraceEvent)) error {
+		return tracePicrSolve(p, emit)
+	})
+}
+
+// TracePicrSearch enumerates up to `limit` solutions of a puzzle as Search does,
+// writing every step of the line logic and every guess and backtrack as JSON Lines.
+// The trace ends "solved" when some solution was found, or "contradiction" with ErrPicrNoSolution otherwise.
+func TracePicrSearch(w io.Writer, p *PicrPuzzle, limit int) (*PicrSearchResult, error) {
+	var r *PicrSearchResult
+	err := tracePicrWrite(w, func(emit func(PicrTraceEvent)) error {
+		var err error
+		r, err = tracePicrSearch(p, limit, emit)
+		return err
+	})
+	return r, err
+}
+
+// tracePicrWrite writes as JSON Lines the events emitted by `trace`, returning its error once the trace is complete.
+func tracePicrWrite(w io.Writer, trace func(emit func(PicrTraceEvent)) error) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	var writeErr error
+	traceErr := trace(func(ev PicrTraceEvent) {
+		if writeErr == nil {
+			writeErr = enc.Encode(ev)
+		}
+	})
+	if writeErr != nil {
+		return writeErr
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return traceErr
+}
+
+// picrTracer reports the steps of the solvers it is attached to.
+type picrTracer struct {
+	p    *PicrPuzzle
+	emit func(PicrTraceEvent)
+	// Rounds are announced by their first line, so that no empty round precedes the end of a stalled solve.
+	round, announced uint
+}
+
+// attach hooks a tracer into a solver, counting its rounds from 1.
+func (t *picrTracer) attach(s *PicrSolver) {
+	t.round, t.announced = 1, 0
+	lineHook := func(axis string, clues [][]uint) func(uint, []CellState, []CellState, error) {
+		return func(idx uint, before []CellState, after []CellState, err error) {
+			if t.announced < t.round {
+				t.announced = t.round
+				t.emit(PicrTraceEvent{Event: PicrTraceRound, Round: t.round})
+			}
+			ev := PicrTraceEvent{Event: PicrTraceLine, Round: t.round, Axis: axis, Index: idx + 1, Clue: clues[idx],
+				Before: picrFormatRow(before), After: picrFormatRow(after)}
+			if err != nil {
+				ev.Error = err.Error()
+			}
+			t.emit(ev)
+			for k, v := range after {
+				if before[k] != Any || v == Any {
+					continue
+				}
+				ev := PicrTraceEvent{Event: PicrTraceCell, Round: t.round, Axis: axis, Index: idx + 1, Row: idx + 1, Col: uint(k) + 1, Value: picrFormatRow([]CellState{v})}
+				if axis == "col" {
+					ev.Row, ev.Col = uint(k)+1, idx+1
+				}
+				t.emit(ev)
+			}
+		}
+	}
+	s.row.lineHook = lineHook("row", t.p.RowClues)
+	s.col.lineHook = lineHook("col", t.p.ColClues)
+	s.roundHook = func() {
+		t.round += 1
+	}
+}
+
+// start emits the start event of a trace.
+func (t *picrTracer) start() {
+	p := t.p
+	t.emit(PicrTraceEvent{Event: PicrTraceStart, Width: p.Width(), Height: p.Height(), RowClues: p.RowClues, ColClues: p.ColClues})
+}
+
+// end emits the end event of a trace for the error of the solver.
+func (t *picrTracer) end(err error) {
+	end := PicrTraceEvent{Event: PicrTraceEnd, Round: t.announced, Status: PicrTraceSolved}
+	if err != nil {
+		end.Status = PicrTraceContradiction
+		if errors.Is(err, ErrPicrDubious) {
+			end.Status = PicrTraceStalled
+		}
+		end.Error = err.Error()
+	}
+	t.emit(end)
+}
+
+// tracePicrSolve solves a puzzle, reporting every step to `emit`.
+func tracePicrSolve(p *PicrPuzzle, emit func(PicrTraceEvent)) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	s, err := p.NewSolver(nil)
+	if err != nil {
+		return err
+	}
+	t := &picrTracer{p: p, emit: emit}
+	t.start()
+	t.attach(s)
+	err = s.solve()
+	t.end(err)
+	return err
+}
+
+// tracePicrSearch searches the solutions of a puzzle, reporting every step to `emit`.
+func tracePicrSearch(p *PicrPuzzle, limit int, emit func(PicrTraceEvent)) (*PicrSearchResult, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	t := &picrTracer{p: p, emit: emit}
+	t.start()
+	step := func(event string) func(int, int, CellState, uint) {
+		return func(row, col int, v CellState, depth uint) {
+			emit(PicrTraceEvent{Event: event, Row: uint(row) + 1, Col: uint(col) + 1, Value: picrFormatRow([]CellState{v}), Depth: depth})
+		}
+	}
+	r := &PicrSearchResult{}
+	hooks := &picrSearchHooks{solver: t.attach, guess: step(PicrTraceGuess), backtrack: step(PicrTraceBacktrack),
+		solution: func([][]CellState) {
+			emit(PicrTraceEvent{Event: PicrTraceSolution, Index: uint(len(r.Solutions))})
+		}}
+	if err := p.search(nil, 0, limit, r, hooks); err != nil {
+		return nil, err
+	}
+	var err error
+	if len(r.Solutions) == 0 {
+		err = ErrPicrNoSolution
+	}
+	t.end(err)
+	return r, err
+}
+
+// ReplayPicrTrace reads a trace written by TracePicrSolve or TracePicrSearch,
+// calling `visit` after each event with the grid as known at that point.
+// The grid is reused between calls.
+func ReplayPicrTrace(r io.Reader, visit func(ev PicrTraceEvent, grid [][]CellState) error) error {
+	dec := json.NewDecoder(r)
+	var grid [][]CellState
+	// guesses holds the grids as they were before each guess not undone yet.
+	var guesses [][][]CellState
+	for {
+		var ev PicrTraceEvent
+		if err := dec.Decode(&ev); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("PicrTrace: %v", err)
+		}
+		switch ev.Event {
+		case PicrTraceStart:
+			grid = make([][]CellState, ev.Height)
+			for i := range grid {
+				grid[i] = make([]CellState, ev.Width)
+			}
+		case PicrTraceCell, PicrTraceGuess:
+			if grid == nil || ev.Row < 1 || ev.Row > uint(len(grid)) || ev.Col < 1 || ev.Col > uint(len(grid[0])) {
+				return fmt.Errorf("PicrTrace: cell (%d, %d) out of bounds", ev.Row, ev.Col)
+			}
+			v, err := picrParseRow(ev.Value)
+			if err != nil || len(v) != 1 {
+				return fmt.Errorf("PicrTrace: invalid cell value %q", ev.Value)
+			}
+			if ev.Event == PicrTraceGuess {
+				guesses = append(guesses, picrCopyMap(grid))
+			}
+			grid[ev.Row-1][ev.Col-1] = v[0]
+		case PicrTraceBacktrack:
+			if len(guesses) == 0 {
+				return errors.New("PicrTrace: backtrack without a guess")
+			}
+			last := guesses[len(guesses)-1]
+			guesses = guesses[:len(guesses)-1]
+			for i := range grid {
+				copy(grid[i], last[i])
+			}
+		}
+		if grid == nil {
+			return errors.New("PicrTrace: trace does not begin with a start event")
+		}
+		if err := visit(ev, grid); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package picross
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestTracePicrSolve(t *testing.T) {
+	var buf bytes.Buffer
+	if err := TracePicrSolve(&buf, horsePuzzle()); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	var rounds, lines, cells int
+	var last PicrTraceEvent
+	var grid [][]CellState
+	err := ReplayPicrTrace(&buf, func(ev PicrTraceEvent, g [][]CellState) error {
+		switch ev.Event {
+		case PicrTraceRound:
+			rounds += 1
+			if ev.Round != uint(rounds) {
+				t.Errorf(`unexpected round number: %v`, ev.Round)
+			}
+		case PicrTraceLine:
+			lines += 1
+		case PicrTraceCell:
+			cells += 1
+		}
+		last, grid = ev, g
+		return nil
+	})
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if cells != 25 {
+		t.Errorf(`unexpected amount of cells: %v`, cells)
+	}
+	if lines != 10*rounds-5 {
+		t.Errorf(`unexpected amount of lines: %v in %v rounds`, lines, rounds)
+	}
+	if last.Event != PicrTraceEnd || last.Status != PicrTraceSolved || last.Round != uint(rounds) {
+		t.Errorf(`unexpected end event: %+v`, last)
+	}
+	expected := str2Map(`###..
+                         .#..#
+                         .####
+                         .###.
+                         .#.#.`)
+	if !areSlices2Equal(grid, expected) {
+		t.Errorf(`replayed grid mismatch: expected %v, got %v`, expected, grid)
+	}
+}
+
+func TestTracePicrSolveFail(t *testing.T) {
+	checks := map[string]*PicrPuzzle{
+		PicrTraceStalled:       {RowClues: [][]uint{{1}, {1}}, ColClues: [][]uint{{1}, {1}}},
+		PicrTraceContradiction: {RowClues: [][]uint{{1}, {2}}, ColClues: [][]uint{{2}, {2}}},
+	}
+	for status, p := range checks {
+		var buf bytes.Buffer
+		err := TracePicrSolve(&buf, p)
+		if err == nil {
+			t.Errorf(`%v: unexpected success`, status)
+		}
+		if (status == PicrTraceStalled) != errors.Is(err, ErrPicrDubious) {
+			t.Errorf(`%v: unexpected error: %v`, status, err)
+		}
+		var failing, last PicrTraceEvent
+		ReplayPicrTrace(&buf, func(ev PicrTraceEvent, g [][]CellState) error {
+			if ev.Event == PicrTraceLine && ev.Error != `` {
+				failing = ev
+			}
+			last = ev
+			return nil
+		})
+		if last.Event != PicrTraceEnd || last.Status != status || last.Error == `` {
+			t.Errorf(`%v: unexpected end event: %+v`, status, last)
+		}
+		// The line that finds the contradiction is traced too.
+		if (status == PicrTraceContradiction) != (failing.Event == PicrTraceLine) || (failing.Event != `` && failing.After != ``) {
+			t.Errorf(`%v: unexpected failing line: %+v`, status, failing)
+		}
+	}
+}
+
+func TestTracePicrSearch(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := TracePicrSearch(&buf, probePuzzle(), 2)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	var guesses, backtracks, depth int
+	var solutions [][][]CellState
+	var last PicrTraceEvent
+	err = ReplayPicrTrace(&buf, func(ev PicrTraceEvent, g [][]CellState) error {
+		switch ev.Event {
+		case PicrTraceGuess:
+			guesses += 1
+			depth += 1
+			if ev.Depth != uint(depth) || g[ev.Row-1][ev.Col-1] == Any {
+				t.Errorf(`unexpected guess: %+v at depth %v`, ev, depth)
+			}
+		case PicrTraceBacktrack:
+			backtracks += 1
+			if ev.Depth != uint(depth) || g[ev.Row-1][ev.Col-1] != Any {
+				t.Errorf(`unexpected backtrack: %+v at depth %v`, ev, depth)
+			}
+			depth -= 1
+		case PicrTraceSolution:
+			solutions = append(solutions, picrCopyMap(g))
+		}
+		last = ev
+		return nil
+	})
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if guesses == 0 || guesses != int(r.Guesses) || backtracks != guesses || depth != 0 {
+		t.Errorf(`unexpected guesses: %v, backtracks: %v, result: %+v`, guesses, backtracks, r)
+	}
+	if len(solutions) != 1 || !areSlices2Equal(solutions[0], r.Solutions[0]) {
+		t.Errorf(`replayed solutions mismatch: %v`, solutions)
+	}
+	if last.Event != PicrTraceEnd || last.Status != PicrTraceSolved {
+		t.Errorf(`unexpected end event: %+v`, last)
+	}
+	buf.Reset()
+	p := &PicrPuzzle{RowClues: [][]uint{{2}, {2}}, ColClues: [][]uint{{1}, {2}}}
+	if _, err := TracePicrSearch(&buf, p, 2); err != ErrPicrNoSolution {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+}
@@ -0,0 +1,183 @@
+package picross
+
+import (
+	"fmt"
+)
+
+// Inverse returns the symmetry that undoes a symmetry.
+func (s PicrSymmetry) Inverse() PicrSymmetry {
+	switch s {
+	case PicrRotate90:
+		return PicrRotate270
+	case PicrRotate270:
+		return PicrRotate90
+	}
+	return s
+}
+
+// PicrTransformClues returns the row and column clues of a puzzle transformed by a symmetry.
+// The given clues are left untouched.
+func PicrTransformClues(rowClues, colClues [][]uint, s PicrSymmetry) ([][]uint, [][]uint) {
+	return picrSymmetryClues(rowClues, colClues, s)
+}
+
+// PicrTransformGrid returns a copy of a grid transformed by a symmetry.
+func PicrTransformGrid(grid [][]CellState, s PicrSymmetry) [][]CellState {
+	return picrSymmetryGrid(grid, s)
+}
+
+// Transform returns a puzzle rotated, mirrored or transposed by a symmetry, along with its goal, if any.
+// Solving the transformed puzzle yields the transformed solution of the original one.
+func (p *PicrPuzzle) Transform(s PicrSymmetry) *PicrPuzzle {
+	q := &PicrPuzzle{Title: p.Title}
+	q.RowClues, q.ColClues = picrSymmetryClues(p.RowClues, p.ColClues, s)
+	if p.Goal != nil {
+		q.Goal = picrSymmetryGrid(p.Goal, s)
+	}
+	return q
+}
+
+// PicrInvertGrid returns a copy of a grid with filled cells and gaps swapped; unknown cells stay unknown.
+func PicrInvertGrid(grid [][]CellState) [][]CellState {
+	ans := picrCopyMap(grid)
+	for _, row := range ans {
+		for j, v := range row {
+			switch v {
+			case Fill:
+				row[j] = Gap
+			case Gap:
+				row[j] = Fill
+			}
+		}
+	}
+	return ans
+}
+
+// PicrCropGrid returns a copy of the part of a grid with `height` rows and `width` columns
+// whose top left cell is at (`row`, `col`), counting from zero.
+func PicrCropGrid(grid [][]CellState, row, col, height, width uint) ([][]CellState, error) {
+	if height < 1 || width < 1 || len(grid) < 1 || row+height > uint(len(grid)) || col+width > uint(len(grid[0])) {
+		return nil, fmt.Errorf("PicrPuzzle: cannot crop %dx%d cells at row %d, column %d", width, height, row+1, col+1)
+	}
+	ans := make([][]CellState, height)
+	for i := range ans {
+		ans[i] = append([]CellState{}, grid[row+uint(i)][col:col+width]...)
+	}
+	return ans, nil
+}
+
+// PicrPadGrid returns a copy of a grid surrounded by gaps.
+func PicrPadGrid(grid [][]CellState, top, right, bottom, left uint) [][]CellState {
+	width := left + right
+	if len(grid) > 0 {
+		width += uint(len(grid[0]))
+	}
+	ans := make([][]CellState, 0, top+uint(len(grid))+bottom)
+	gaps := func() []CellState {
+		row := make([]CellState, width)
+		for j := range row {
+			row[j] = Gap
+		}
+		return row
+	}
+	for i := uint(0); i < top; i++ {
+		ans = append(ans, gaps())
+	}
+	for _, src := range grid {
+		row := gaps()
+		copy(row[left:], src)
+		ans = append(ans, row)
+	}
+	for i := uint(0); i < bottom; i++ {
+		ans = append(ans, gaps())
+	}
+	return ans
+}
+
+// PicrPadClues returns the row and column clues of a puzzle surrounded by empty lines.
+// The given clues are left untouched.
+func PicrPadClues(rowClues, colClues [][]uint, top, right, bottom, left uint) ([][]uint, [][]uint) {
+	pad := func(clues [][]uint, before, after uint) [][]uint {
+		ans := make([][]uint, 0, before+uint(len(clues))+after)
+		for i := uint(0); i < before; i++ {
+			ans = append(ans, make([]uint, 0))
+		}
+		for _, clue := range clues {
+			ans = append(ans, append([]uint{}, clue...))
+		}
+		for i := uint(0); i < after; i++ {
+			ans = append(ans, make([]uint, 0))
+		}
+		return ans
+	}
+	return pad(rowClues, top, bottom), pad(colClues, left, right)
+}
+
+// Pad returns a puzzle surrounded by empty lines, along with its goal, if any.
+// Solving the padded puzzle yields the padded solution of the original one.
+func (p *PicrPuzzle) Pad(top, right, bottom, left uint) *PicrPuzzle {
+	q := &PicrPuzzle{Title: p.Title}
+	q.RowClues, q.ColClues = PicrPadClues(p.RowClues, p.ColClues, top, right, bottom, left)
+	if p.Goal != nil {
+		q.Goal = PicrPadGrid(p.Goal, top, right, bottom, left)
+	}
+	return q
+}
+
+// solution returns the goal of a puzzle or, lacking it, its unique solution.
+func (p *PicrPuzzle) solution() ([][]CellState, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	if p.Goal != nil {
+		return p.Goal, nil
+	}
+	r, err := p.Search(2)
+	if err != nil {
+		return nil, err
+	}
+	switch len(r.Solutions) {
+	case 0:
+		return nil, ErrPicrNoSolution
+	case 1:
+		return r.Solutions[0], nil
+	}
+	return nil, ErrPicrAmbiguous
+}
+
+// Invert returns the puzzle whose goal is the goal of a puzzle with filled cells and gaps swapped.
+// The clues of the inverted puzzle depend on the picture rather than on the clues,
+// so a puzzle without goal must have a unique solution.
+// The inverted puzzle may have other solutions besides the inverted goal.
+func (p *PicrPuzzle) Invert() (*PicrPuzzle, error) {
+	goal, err := p.solution()
+	if err != nil {
+		return nil, err
+	}
+	q, err := NewPicrPuzzleFromGoal(PicrInvertGrid(goal))
+	if err != nil {
+		return nil, err
+	}
+	q.Title = p.Title
+	return q, nil
+}
+
+// Crop returns the puzzle whose goal is a part of the goal of a puzzle, as PicrCropGrid.
+// A puzzle without goal must have a unique solution.
+// The cropped puzzle may have other solutions besides the cropped goal.
+func (p *PicrPuzzle) Crop(row, col, height, width uint) (*PicrPuzzle, error) {
+	goal, err := p.solution()
+	if err != nil {
+		return nil, err
+	}
+	part, err := PicrCropGrid(goal, row, col, height, width)
+	if err != nil {
+		return nil, err
+	}
+	q, err := NewPicrPuzzleFromGoal(part)
+	if err != nil {
+		return nil, err
+	}
+	q.Title = p.Title
+	return q, nil
+}
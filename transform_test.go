@@ -0,0 +1,124 @@
+package picross
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// transformPuzzles returns random uniquely solvable puzzles of several sizes, with their goals.
+func transformPuzzles(t *testing.T) []*PicrPuzzle {
+	var ans []*PicrPuzzle
+	for seed := int64(1); seed <= 12; seed++ {
+		g, err := NewPicrGenerator(PicrGenerateOptions{Width: 3 + uint(seed%5), Height: 2 + uint(seed%4), Seed: seed})
+		if err != nil {
+			t.Fatalf(`unexpected error: %v`, err)
+		}
+		p, err := g.Next()
+		if err != nil {
+			t.Fatalf(`unexpected error: %v`, err)
+		}
+		ans = append(ans, p)
+	}
+	return ans
+}
+
+// checkTransformSolution verifies that a transformed puzzle, stripped of its goal, has the expected solution
+// among its solutions, and that it is the only one when `unique`.
+func checkTransformSolution(t *testing.T, what string, q *PicrPuzzle, expected [][]CellState, unique bool) {
+	t.Helper()
+	stripped := &PicrPuzzle{RowClues: q.RowClues, ColClues: q.ColClues}
+	r, err := stripped.Search(1000)
+	if err != nil {
+		t.Errorf(`%s: unexpected error: %v`, what, err)
+		return
+	}
+	if unique && len(r.Solutions) != 1 {
+		t.Errorf(`%s: expected a unique solution, got %v`, what, len(r.Solutions))
+	}
+	for _, s := range r.Solutions {
+		if areSlices2Equal(s, expected) {
+			return
+		}
+	}
+	t.Errorf(`%s: solution %v not found among %v`, what, expected, r.Solutions)
+}
+
+func TestPicrTransformProperties(t *testing.T) {
+	for _, p := range transformPuzzles(t) {
+		for _, s := range PicrSymmetries {
+			q := p.Transform(s)
+			checkTransformSolution(t, s.String(), q, PicrTransformGrid(p.Goal, s), true)
+			if !areSlices2Equal(q.Goal, PicrTransformGrid(p.Goal, s)) {
+				t.Errorf(`%v: goal mismatch: %v`, s, q.Goal)
+			}
+			back := q.Transform(s.Inverse())
+			if !areSlices2Equal(back.Goal, p.Goal) || !areSlices2Equal(back.RowClues, p.RowClues) || !areSlices2Equal(back.ColClues, p.ColClues) {
+				t.Errorf(`%v: inverse does not restore the puzzle: %v`, s, back)
+			}
+		}
+	}
+}
+
+func TestPicrPadProperties(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, p := range transformPuzzles(t) {
+		top, right, bottom, left := uint(rnd.Intn(3)), uint(rnd.Intn(3)), uint(rnd.Intn(3)), uint(rnd.Intn(3))
+		q := p.Pad(top, right, bottom, left)
+		if q.Width() != p.Width()+left+right || q.Height() != p.Height()+top+bottom {
+			t.Errorf(`unexpected size: %vx%v`, q.Width(), q.Height())
+		}
+		checkTransformSolution(t, `pad`, q, PicrPadGrid(p.Goal, top, right, bottom, left), true)
+		if !areSlices2Equal(q.Goal, PicrPadGrid(p.Goal, top, right, bottom, left)) {
+			t.Errorf(`pad: goal mismatch: %v`, q.Goal)
+		}
+	}
+}
+
+func TestPicrInvertProperties(t *testing.T) {
+	for _, p := range transformPuzzles(t) {
+		q, err := p.Invert()
+		if err != nil {
+			t.Fatalf(`unexpected error: %v`, err)
+		}
+		expected := PicrInvertGrid(p.Goal)
+		checkTransformSolution(t, `invert`, q, expected, false)
+		if !areSlices2Equal(q.Goal, expected) || !areSlices2Equal(PicrInvertGrid(q.Goal), p.Goal) {
+			t.Errorf(`invert: goal mismatch: %v`, q.Goal)
+		}
+	}
+	// Without goal, the unique solution is inverted.
+	q, err := horsePuzzle().Invert()
+	if err != nil || !areSlices2Equal(q.Goal, PicrInvertGrid(horseGoal())) {
+		t.Errorf(`unexpected result: %v %v`, q, err)
+	}
+	ambiguous := &PicrPuzzle{RowClues: [][]uint{{1}, {1}}, ColClues: [][]uint{{1}, {1}}}
+	if _, err := ambiguous.Invert(); err != ErrPicrAmbiguous {
+		t.Errorf(`unexpected error: %v`, err)
+	}
+}
+
+func TestPicrCropProperties(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	for _, p := range transformPuzzles(t) {
+		height, width := 1+uint(rnd.Intn(int(p.Height()))), 1+uint(rnd.Intn(int(p.Width())))
+		row, col := uint(rnd.Intn(int(p.Height()-height+1))), uint(rnd.Intn(int(p.Width()-width+1)))
+		q, err := p.Crop(row, col, height, width)
+		if err != nil {
+			t.Fatalf(`unexpected error: %v`, err)
+		}
+		expected, _ := PicrCropGrid(p.Goal, row, col, height, width)
+		checkTransformSolution(t, `crop`, q, expected, false)
+		if !areSlices2Equal(q.Goal, expected) || q.Width() != width || q.Height() != height {
+			t.Errorf(`crop: goal mismatch: %v`, q.Goal)
+		}
+	}
+	q, err := horsePuzzle().Crop(2, 1, 3, 3)
+	if err != nil || !areSlices2Equal(q.Goal, str2Map(`###
+                                                       ###
+                                                       #.#`)) {
+		t.Errorf(`unexpected result: %v %v`, q, err)
+	}
+	if _, err := horsePuzzle().Crop(3, 0, 3, 1); err == nil {
+		t.Errorf(`unexpected success cropping outside the grid`)
+	}
+}
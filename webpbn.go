@@ -0,0 +1,207 @@
+package picross
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// webpbnPuzzle mirrors the `puzzle` element of the webpbn XML format (pbn-0.3.dtd).
+type webpbnPuzzle struct {
+	XMLName      xml.Name         `xml:"puzzle"`
+	Type         string           `xml:"type,attr,omitempty"`
+	DefaultColor string           `xml:"defaultcolor,attr,omitempty"`
+	Title        string           `xml:"title,omitempty"`
+	Colors       []webpbnColor    `xml:"color"`
+	Clues        []webpbnClues    `xml:"clues"`
+	Solutions    []webpbnSolution `xml:"solution"`
+}
+
+type webpbnColor struct {
+	Name  string `xml:"name,attr"`
+	Char  string `xml:"char,attr"`
+	Value string `xml:",chardata"`
+}
+
+type webpbnClues struct {
+	Type  string       `xml:"type,attr"`
+	Lines []webpbnLine `xml:"line"`
+}
+
+type webpbnLine struct {
+	Counts []webpbnCount `xml:"count"`
+}
+
+type webpbnCount struct {
+	Color string `xml:"color,attr,omitempty"`
+	Value uint   `xml:",chardata"`
+}
+
+type webpbnSolution struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Image string `xml:"image"`
+}
+
+// webpbnPuzzleSet is the usual root element of webpbn XML files.
+type webpbnPuzzleSet struct {
+	XMLName xml.Name       `xml:"puzzleset"`
+	Puzzles []webpbnPuzzle `xml:"puzzle"`
+}
+
+// ParseWebpbn reads the first puzzle of a webpbn XML document,
+// whose root is either a `puzzleset` or a single `puzzle`.
+// Only black and white puzzles are supported;
+// the goal is taken from the `solution` of type "goal", if any.
+func ParseWebpbn(r io.Reader) (*PicrPuzzle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var wp webpbnPuzzle
+	var set webpbnPuzzleSet
+	if err := xml.Unmarshal(data, &set); err == nil {
+		if len(set.Puzzles) < 1 {
+			return nil, errors.New("webpbn: empty puzzle set")
+		}
+		wp = set.Puzzles[0]
+	} else if err := xml.Unmarshal(data, &wp); err != nil {
+		return nil, fmt.Errorf("webpbn: %v", err)
+	}
+	if wp.Type != "" && wp.Type != "grid" {
+		return nil, fmt.Errorf("webpbn: unsupported puzzle type %q", wp.Type)
+	}
+	fill := wp.DefaultColor
+	if fill == "" {
+		fill = "black"
+	}
+	p := &PicrPuzzle{Title: strings.TrimSpace(wp.Title)}
+	for _, clues := range wp.Clues {
+		lines := make([][]uint, len(clues.Lines))
+		for i, line := range clues.Lines {
+			lines[i] = make([]uint, 0, len(line.Counts))
+			for _, count := range line.Counts {
+				if count.Color != "" && count.Color != fill {
+					return nil, errors.New("webpbn: color puzzles are not supported")
+				}
+				lines[i] = append(lines[i], count.Value)
+			}
+		}
+		switch clues.Type {
+		case "rows":
+			p.RowClues = lines
+		case "columns":
+			p.ColClues = lines
+		default:
+			return nil, fmt.Errorf("webpbn: unexpected clues type %q", clues.Type)
+		}
+	}
+	for _, sol := range wp.Solutions {
+		if sol.Type != "" && sol.Type != "goal" {
+			continue
+		}
+		goal, err := parseWebpbnImage(sol.Image, wp.Colors, fill)
+		if err != nil {
+			return nil, err
+		}
+		p.Goal = goal
+		break
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FormatWebpbn writes a puzzle as a webpbn XML puzzle set.
+func FormatWebpbn(w io.Writer, p *PicrPuzzle) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	wp := webpbnPuzzle{
+		Type:         "grid",
+		DefaultColor: "black",
+		Title:        p.Title,
+		Colors:       []webpbnColor{{Name: "white", Char: ".", Value: "fff"}, {Name: "black", Char: "X", Value: "000"}},
+		Clues:        []webpbnClues{newWebpbnClues("columns", p.ColClues), newWebpbnClues("rows", p.RowClues)},
+	}
+	if p.Goal != nil {
+		var b strings.Builder
+		b.WriteByte('\n')
+		for _, row := range p.Goal {
+			b.WriteByte('|')
+			for _, v := range row {
+				switch v {
+				case Fill:
+					b.WriteByte('X')
+				case Gap:
+					b.WriteByte('.')
+				default:
+					b.WriteByte('?')
+				}
+			}
+			b.WriteString("|\n")
+		}
+		wp.Solutions = []webpbnSolution{{Type: "goal", Image: b.String()}}
+	}
+	if _, err := io.WriteString(w, xml.Header+`<!DOCTYPE pbn SYSTEM "https://webpbn.com/pbn-0.3.dtd">`+"\n"); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(webpbnPuzzleSet{Puzzles: []webpbnPuzzle{wp}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func newWebpbnClues(kind string, clues [][]uint) webpbnClues {
+	ans := webpbnClues{Type: kind, Lines: make([]webpbnLine, len(clues))}
+	for i, clue := range clues {
+		for _, v := range clue {
+			if v == 0 {
+				continue
+			}
+			ans.Lines[i].Counts = append(ans.Lines[i].Counts, webpbnCount{Value: v})
+		}
+	}
+	return ans
+}
+
+// parseWebpbnImage decodes a solution image, made of rows of color characters enclosed by '|'.
+func parseWebpbnImage(image string, colors []webpbnColor, fill string) ([][]CellState, error) {
+	fillChar, gapChar := "X", "."
+	for _, c := range colors {
+		switch c.Name {
+		case fill:
+			fillChar = c.Char
+		case "white":
+			gapChar = c.Char
+		}
+	}
+	ans := make([][]CellState, 0)
+	for _, line := range strings.Split(image, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		line = strings.Trim(line, "|")
+		row := make([]CellState, 0, len(line))
+		for _, c := range line {
+			switch string(c) {
+			case fillChar:
+				row = append(row, Fill)
+			case gapChar:
+				row = append(row, Gap)
+			case "?":
+				row = append(row, Any)
+			default:
+				return nil, fmt.Errorf("webpbn: unexpected solution character %q", c)
+			}
+		}
+		ans = append(ans, row)
+	}
+	return ans, nil
+}
@@ -0,0 +1,63 @@
+package picross
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseWebpbn(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<!DOCTYPE pbn SYSTEM "https://webpbn.com/pbn-0.3.dtd">
+<puzzleset>
+<puzzle type="grid" defaultcolor="black">
+<title>Tiny</title>
+<color name="white" char=".">fff</color>
+<color name="black" char="X">000</color>
+<clues type="columns"><line><count>2</count></line><line></line><line><count>1</count></line></clues>
+<clues type="rows"><line><count>1</count><count>1</count></line><line><count>1</count></line></clues>
+<solution type="goal"><image>
+|X.X|
+|X..|
+</image></solution>
+</puzzle>
+</puzzleset>`
+	p, err := ParseWebpbn(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	expRows := [][]uint{{1, 1}, {1}}
+	expCols := [][]uint{{2}, {}, {1}}
+	if p.Title != `Tiny` || !areSlices2Equal(p.RowClues, expRows) || !areSlices2Equal(p.ColClues, expCols) {
+		t.Errorf(`puzzle mismatch: %v`, p)
+	}
+	expGoal := str2Map(`#.#
+                        #..`)
+	if !areSlices2Equal(p.Goal, expGoal) {
+		t.Errorf(`goal mismatch: expected %v, got %v`, expGoal, p.Goal)
+	}
+	var buf bytes.Buffer
+	if err := FormatWebpbn(&buf, p); err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	q, err := ParseWebpbn(&buf)
+	if err != nil {
+		t.Fatalf(`unexpected error: %v`, err)
+	}
+	if q.Title != p.Title || !areSlices2Equal(q.ColClues, p.ColClues) || !areSlices2Equal(q.Goal, p.Goal) {
+		t.Errorf(`round trip mismatch: %v`, q)
+	}
+}
+
+func TestParseWebpbnFail(t *testing.T) {
+	for _, doc := range []string{
+		`<puzzleset></puzzleset>`,
+		`<puzzle type="triddler"></puzzle>`,
+		`<puzzle><clues type="rows"><line><count color="red">1</count></line></clues><clues type="columns"><line><count>1</count></line></clues></puzzle>`,
+		`<puzzle><clues type="rows"><line><count>2</count></line></clues><clues type="columns"><line><count>1</count></line></clues></puzzle>`,
+	} {
+		if _, err := ParseWebpbn(strings.NewReader(doc)); err == nil {
+			t.Errorf(`unexpected success for %q`, doc)
+		}
+	}
+}